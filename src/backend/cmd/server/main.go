@@ -15,12 +15,16 @@ import (
 	"github.com/nodetl/nodetl/internal/domain"
 	"github.com/nodetl/nodetl/internal/executor"
 	"github.com/nodetl/nodetl/internal/handler"
+	"github.com/nodetl/nodetl/internal/metrics"
 	"github.com/nodetl/nodetl/internal/middleware"
+	"github.com/nodetl/nodetl/internal/redact"
 	"github.com/nodetl/nodetl/internal/repository"
 	"github.com/nodetl/nodetl/internal/service"
+	"github.com/nodetl/nodetl/internal/tracing"
 	"github.com/nodetl/nodetl/pkg/ai"
 	"github.com/nodetl/nodetl/pkg/logger"
 	"github.com/nodetl/nodetl/pkg/mongodb"
+	"github.com/nodetl/nodetl/pkg/secrets"
 )
 
 func main() {
@@ -38,6 +42,12 @@ func main() {
 
 	logger.Log.Info("Starting NodeTL...")
 
+	// Trace executions, their nodes and the requests of HTTP nodes
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing)
+	if err != nil {
+		logger.Log.Fatalw("Failed to initialize tracing", "error", err)
+	}
+
 	// Connect to MongoDB
 	mongoClient, err := mongodb.NewClient(cfg.MongoDB.URI, cfg.MongoDB.Database)
 	if err != nil {
@@ -67,6 +77,9 @@ func main() {
 	refreshTokenRepo := repository.NewRefreshTokenRepository(mongoClient)
 	invitationRepo := repository.NewInvitationRepository(mongoClient)
 	settingsRepo := repository.NewSettingsRepository(mongoClient)
+	executionJobRepo := repository.NewExecutionJobRepository(mongoClient)
+	credentialRepo := repository.NewCredentialRepository(mongoClient)
+	scheduleLeaseRepo := repository.NewScheduleLeaseRepository(mongoClient)
 
 	// Seed predefined data
 	ctx := context.Background()
@@ -91,7 +104,54 @@ func main() {
 	// Initialize services
 	mappingService := ai.NewMappingService(&cfg.AI)
 	aiService := service.NewAIService()
-	flowExecutor := executor.NewFlowExecutor(workflowRepo, executionRepo, nodeSchemaRepo)
+
+	// Credentials are encrypted with CREDENTIALS_ENCRYPTION_KEY; without it they are disabled
+	var credentialCipher *secrets.Cipher
+	if cfg.Credentials.EncryptionKey == "" {
+		logger.Log.Warn("CREDENTIALS_ENCRYPTION_KEY is not set, credentials are disabled")
+	} else if credentialCipher, err = secrets.NewCipher(cfg.Credentials.EncryptionKey); err != nil {
+		logger.Log.Fatalw("Invalid CREDENTIALS_ENCRYPTION_KEY", "error", err)
+	}
+	credentialService := service.NewCredentialService(credentialRepo, credentialCipher)
+
+	// Server-wide retention defaults; workflows and projects can override them
+	keepPayloads := cfg.Execution.RetentionKeepPayloads
+	retention := domain.RetentionPolicy{
+		MaxAgeDays:             cfg.Execution.RetentionDays,
+		MaxCount:               cfg.Execution.RetentionMaxCount,
+		KeepSuccessfulPayloads: &keepPayloads,
+	}
+
+	// Sensitive values are redacted before execution data is stored or returned
+	redactionHeaders := cfg.Redaction.Headers
+	if len(redactionHeaders) == 0 {
+		redactionHeaders = redact.DefaultHeaders
+	}
+	redaction, err := redact.New(redactionHeaders, cfg.Redaction.Paths)
+	if err != nil {
+		logger.Log.Fatalw("Invalid REDACTION_PATHS", "error", err)
+	}
+
+	flowExecutor := executor.NewFlowExecutor(workflowRepo, executionRepo, nodeSchemaRepo, projectRepo, credentialService, retention, redaction, credentialCipher)
+	workerPool := executor.NewWorkerPool(flowExecutor, executionJobRepo, cfg.Execution.Workers, cfg.Execution.PollInterval)
+	workerPool.Start()
+
+	// Recover executions left running by crashed instances
+	reaper := executor.NewReaper(flowExecutor, executionJobRepo, cfg.Execution.StaleAfter, cfg.Execution.ReaperInterval, cfg.Execution.MaxRecoveries)
+	reaper.Start()
+
+	// Delete executions past their workflow's retention policy
+	purger := executor.NewPurger(flowExecutor, cfg.Execution.PurgeInterval)
+	purger.Start()
+
+	// Instances share schedules through leases, so each tick runs once
+	var scheduler *executor.Scheduler
+	if cfg.Scheduler.Enabled {
+		scheduler = executor.NewScheduler(flowExecutor, workflowRepo, scheduleLeaseRepo, cfg.Scheduler.SyncInterval)
+		if err := scheduler.Start(ctx); err != nil {
+			logger.Log.Fatalw("Failed to start scheduler", "error", err)
+		}
+	}
 
 	// Auth services
 	authService := service.NewAuthService(userRepo, roleRepo, refreshTokenRepo, &cfg.Auth)
@@ -111,12 +171,12 @@ func main() {
 	}
 
 	// Initialize handlers
-	workflowHandler := handler.NewWorkflowHandler(workflowRepo, projectRepo)
+	workflowHandler := handler.NewWorkflowHandler(workflowRepo, projectRepo, flowExecutor, scheduler)
 	schemaHandler := handler.NewSchemaHandler(schemaRepo)
 	nodeTypeHandler := handler.NewNodeTypeHandler(nodeTypeRepo)
 	mappingHandler := handler.NewMappingHandler(mappingRepo, schemaRepo, mappingService)
-	executionHandler := handler.NewExecutionHandler(executionRepo, workflowRepo, flowExecutor)
-	webhookHandler := handler.NewWebhookHandler(flowExecutor)
+	executionHandler := handler.NewExecutionHandler(executionRepo, workflowRepo, flowExecutor, workerPool)
+	webhookHandler := handler.NewWebhookHandler(flowExecutor, workerPool)
 	nodeSchemaHandler := handler.NewNodeSchemaHandler(nodeSchemaRepo)
 	aiHandler := handler.NewAIHandler(aiService)
 	versionHandler := handler.NewVersionHandler(versionRepo)
@@ -128,6 +188,7 @@ func main() {
 	roleHandler := handler.NewRoleHandler(roleRepo, userRepo)
 	invitationHandler := handler.NewInvitationHandler(invitationService)
 	settingsHandler := handler.NewSettingsHandler(settingsRepo)
+	credentialHandler := handler.NewCredentialHandler(credentialService)
 
 	// Setup Gin
 	if cfg.Server.Mode == "release" {
@@ -156,6 +217,14 @@ func main() {
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
 	})
 
+	// Prometheus metrics
+	if cfg.Metrics.Enabled {
+		if cfg.Metrics.Token == "" {
+			logger.Log.Warn("Serving /metrics without METRICS_TOKEN; restrict access to it at the proxy")
+		}
+		r.GET("/metrics", gin.WrapH(metrics.Handler(cfg.Metrics.Token)))
+	}
+
 	// Public routes (no auth required)
 	// Settings (public read)
 	r.GET("/api/v1/settings", settingsHandler.GetSettings)
@@ -219,6 +288,18 @@ func main() {
 			invitations.POST("/:id/resend", invitationHandler.ResendInvitation)
 		}
 
+		// Credentials (with permissions)
+		credentials := api.Group("/credentials")
+		credentials.Use(middleware.RequirePermission(string(domain.PermissionCredentialView)))
+		{
+			credentials.GET("", credentialHandler.ListCredentials)
+			credentials.GET("/types", credentialHandler.ListCredentialTypes)
+			credentials.GET("/:id", credentialHandler.GetCredential)
+			credentials.POST("", middleware.RequirePermission(string(domain.PermissionCredentialCreate)), credentialHandler.CreateCredential)
+			credentials.PUT("/:id", middleware.RequirePermission(string(domain.PermissionCredentialEdit)), credentialHandler.UpdateCredential)
+			credentials.DELETE("/:id", middleware.RequirePermission(string(domain.PermissionCredentialDelete)), credentialHandler.DeleteCredential)
+		}
+
 		// Settings (admin only for edit)
 		settings := api.Group("/settings")
 		{
@@ -236,10 +317,13 @@ func main() {
 			workflows.PUT("/:id", middleware.RequirePermission(string(domain.PermissionWorkflowEdit)), workflowHandler.UpdateWorkflow)
 			workflows.DELETE("/:id", middleware.RequirePermission(string(domain.PermissionWorkflowDelete)), workflowHandler.DeleteWorkflow)
 			workflows.POST("/:id/activate", middleware.RequirePermission(string(domain.PermissionWorkflowEdit)), workflowHandler.ActivateWorkflow)
+			workflows.POST("/:id/validate", workflowHandler.ValidateWorkflow)
 			workflows.POST("/:id/deactivate", middleware.RequirePermission(string(domain.PermissionWorkflowEdit)), workflowHandler.DeactivateWorkflow)
 			workflows.POST("/:id/execute", middleware.RequirePermission(string(domain.PermissionWorkflowEdit)), executionHandler.ExecuteWorkflow)
 			workflows.GET("/:id/executions", middleware.RequirePermission(string(domain.PermissionExecutionView)), executionHandler.ListExecutions)
 			workflows.GET("/:id/executions/latest", middleware.RequirePermission(string(domain.PermissionExecutionView)), executionHandler.GetLatestExecutions)
+			workflows.GET("/:id/stats", middleware.RequirePermission(string(domain.PermissionExecutionView)), executionHandler.GetWorkflowStats)
+			workflows.DELETE("/:id/executions", middleware.RequirePermission(string(domain.PermissionExecutionDelete)), executionHandler.DeleteExecutions)
 		}
 
 		// Schemas (with permissions)
@@ -280,6 +364,9 @@ func main() {
 		executions.Use(middleware.RequirePermission(string(domain.PermissionExecutionView)))
 		{
 			executions.GET("/:id", executionHandler.GetExecution)
+			executions.GET("/:id/stream", executionHandler.StreamExecution)
+			executions.POST("/:id/cancel", middleware.RequirePermission(string(domain.PermissionExecutionEdit)), executionHandler.CancelExecution)
+			executions.POST("/:id/replay", middleware.RequirePermission(string(domain.PermissionExecutionCreate)), executionHandler.ReplayExecution)
 		}
 
 		// Auto-save endpoint (lightweight partial update) - MUST be before /nodes routes
@@ -322,6 +409,7 @@ func main() {
 			projects.PUT("/:id", middleware.RequirePermission(string(domain.PermissionVersionEdit)), projectHandler.Update)
 			projects.DELETE("/:id", middleware.RequirePermission(string(domain.PermissionVersionDelete)), projectHandler.Delete)
 			projects.POST("/:id/lock", middleware.RequirePermission(string(domain.PermissionProjectLock)), projectHandler.ToggleLock)
+			projects.PUT("/:id/environments", middleware.RequirePermission(string(domain.PermissionVersionEdit)), projectHandler.UpdateEnvironments)
 			// Workflows within project
 			projects.POST("/:id/workflows", middleware.RequirePermission(string(domain.PermissionWorkflowEdit)), projectHandler.AddWorkflow)
 			projects.GET("/:id/workflows/:workflowId", projectHandler.GetWorkflow)
@@ -329,6 +417,7 @@ func main() {
 			projects.DELETE("/:id/workflows/:workflowId", middleware.RequirePermission(string(domain.PermissionWorkflowDelete)), projectHandler.DeleteWorkflow)
 			// Executions for project
 			projects.GET("/:id/executions", middleware.RequirePermission(string(domain.PermissionExecutionView)), executionHandler.ListExecutionsByProject)
+			projects.GET("/:id/stats", middleware.RequirePermission(string(domain.PermissionExecutionView)), executionHandler.GetProjectStats)
 		}
 	}
 
@@ -342,6 +431,8 @@ func main() {
 		Addr:    ":" + cfg.Server.Port,
 		Handler: r,
 	}
+	// End open event streams so shutdown does not wait for them
+	srv.RegisterOnShutdown(flowExecutor.Events().Close)
 
 	go func() {
 		logger.Log.Infow("Server starting", "port", cfg.Server.Port)
@@ -364,5 +455,16 @@ func main() {
 		logger.Log.Fatalw("Server forced to shutdown", "error", err)
 	}
 
+	// Let queued executions that are already running finish
+	reaper.Stop()
+	purger.Stop()
+	workerPool.Stop(ctx)
+	if scheduler != nil {
+		scheduler.Stop(ctx)
+	}
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Log.Warnw("Failed to flush traces", "error", err)
+	}
+
 	logger.Log.Info("Server exited")
 }
@@ -2,19 +2,27 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Server   ServerConfig
-	MongoDB  MongoDBConfig
-	AI       AIConfig
-	Auth     AuthConfig
-	SMTP     SMTPConfig
-	App      AppConfig
-	Logging  LoggingConfig
+	Server      ServerConfig
+	MongoDB     MongoDBConfig
+	AI          AIConfig
+	Auth        AuthConfig
+	SMTP        SMTPConfig
+	App         AppConfig
+	Logging     LoggingConfig
+	Execution   ExecutionConfig
+	Scheduler   SchedulerConfig
+	Credentials CredentialsConfig
+	Redaction   RedactionConfig
+	Metrics     MetricsConfig
+	Tracing     TracingConfig
 }
 
 type ServerConfig struct {
@@ -76,6 +84,51 @@ type AppConfig struct {
 	SecondaryColor string
 }
 
+// ExecutionConfig contains settings for background workflow execution
+type ExecutionConfig struct {
+	Workers               int           // Number of workers running queued executions
+	PollInterval          time.Duration // How often idle workers check the queue
+	StaleAfter            time.Duration // Running executions without a heartbeat for this long are recovered
+	ReaperInterval        time.Duration // How often stale executions are looked for
+	MaxRecoveries         int           // Times an idempotent execution is re-queued before it is failed
+	RetentionDays         int           // Default maximum age of executions in days, 0 keeps them forever
+	RetentionMaxCount     int           // Default number of finished executions kept per workflow, 0 for no limit
+	RetentionKeepPayloads bool          // Default for keeping node inputs and outputs of completed executions
+	PurgeInterval         time.Duration // How often retention policies are applied
+}
+
+// SchedulerConfig contains settings for schedule triggers
+type SchedulerConfig struct {
+	Enabled      bool          // Run schedule triggers on this instance
+	SyncInterval time.Duration // How often schedules are reloaded from the database
+}
+
+// CredentialsConfig contains settings for stored credentials
+type CredentialsConfig struct {
+	EncryptionKey string // 32-byte AES key as 64 hex characters or base64; credentials are disabled without it
+}
+
+// RedactionConfig contains the rules hiding sensitive values in stored and
+// returned execution data
+type RedactionConfig struct {
+	Headers []string // Header names to redact; the built-in list is used when empty
+	Paths   []string // Field paths redacted in all executions, e.g. ..password
+}
+
+// MetricsConfig contains settings for the Prometheus metrics endpoint
+type MetricsConfig struct {
+	Enabled bool   // Serve /metrics; off by default, as labels include workflow names
+	Token   string // Bearer token scrapers must send; the endpoint is public without it
+}
+
+// TracingConfig contains settings for OpenTelemetry tracing
+type TracingConfig struct {
+	Exporter    string  // none, stdout, file or otlp
+	File        string  // File spans are written to by the file exporter
+	ServiceName string  // service.name of the spans
+	SampleRatio float64 // Share of new traces recorded; traces started by callers follow their sampling decision
+}
+
 type LoggingConfig struct {
 	Level  string
 	Format string
@@ -145,6 +198,38 @@ func Load() (*Config, error) {
 			Level:  getEnv("LOG_LEVEL", "debug"),
 			Format: getEnv("LOG_FORMAT", "json"),
 		},
+		Execution: ExecutionConfig{
+			Workers:               getEnvInt("EXECUTION_WORKERS", 4),
+			PollInterval:          getEnvDuration("EXECUTION_POLL_INTERVAL", time.Second),
+			StaleAfter:            getEnvDuration("EXECUTION_STALE_AFTER", 2*time.Minute),
+			ReaperInterval:        getEnvDuration("EXECUTION_REAPER_INTERVAL", time.Minute),
+			MaxRecoveries:         getEnvInt("EXECUTION_MAX_RECOVERIES", 3),
+			RetentionDays:         getEnvInt("EXECUTION_RETENTION_DAYS", 0),
+			RetentionMaxCount:     getEnvInt("EXECUTION_RETENTION_MAX_COUNT", 0),
+			RetentionKeepPayloads: getEnv("EXECUTION_RETENTION_KEEP_PAYLOADS", "true") == "true",
+			PurgeInterval:         getEnvDuration("EXECUTION_PURGE_INTERVAL", time.Hour),
+		},
+		Scheduler: SchedulerConfig{
+			Enabled:      getEnv("SCHEDULER_ENABLED", "true") == "true",
+			SyncInterval: getEnvDuration("SCHEDULER_SYNC_INTERVAL", time.Minute),
+		},
+		Credentials: CredentialsConfig{
+			EncryptionKey: getEnv("CREDENTIALS_ENCRYPTION_KEY", ""),
+		},
+		Redaction: RedactionConfig{
+			Headers: getEnvList("REDACTION_HEADERS"),
+			Paths:   getEnvList("REDACTION_PATHS"),
+		},
+		Metrics: MetricsConfig{
+			Enabled: getEnv("METRICS_ENABLED", "false") == "true",
+			Token:   getEnv("METRICS_TOKEN", ""),
+		},
+		Tracing: TracingConfig{
+			Exporter:    getEnv("TRACING_EXPORTER", "none"),
+			File:        getEnv("TRACING_FILE", "traces.jsonl"),
+			ServiceName: getEnv("TRACING_SERVICE_NAME", "nodetl"),
+			SampleRatio: getEnvFloat("TRACING_SAMPLE_RATIO", 1),
+		},
 	}, nil
 }
 
@@ -154,3 +239,35 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvList splits a comma-separated variable, dropping empty entries
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value, err := time.ParseDuration(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
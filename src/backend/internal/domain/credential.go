@@ -0,0 +1,142 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CredentialType identifies how a credential authorizes requests
+type CredentialType string
+
+const (
+	CredentialTypeAPIKey                  CredentialType = "api_key"
+	CredentialTypeBasicAuth               CredentialType = "basic_auth"
+	CredentialTypeBearerToken             CredentialType = "bearer_token"
+	CredentialTypeOAuth2ClientCredentials CredentialType = "oauth2_client_credentials"
+)
+
+// Credential holds the secret needed to call an external service. Nodes
+// refer to it by ID. Only the non-secret settings are returned by the API;
+// the secret values are stored encrypted and never leave the server.
+type Credential struct {
+	ID              primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name            string             `json:"name" bson:"name"`
+	Description     string             `json:"description,omitempty" bson:"description,omitempty"`
+	Type            CredentialType     `json:"type" bson:"type"`
+	Config          map[string]string  `json:"config,omitempty" bson:"config,omitempty"` // Non-secret settings, e.g. the header name or token URL
+	SecretKeys      []string           `json:"secretKeys" bson:"secret_keys"`            // Names of the secret values that are set
+	EncryptedSecret string             `json:"-" bson:"encrypted_secret"`                // Secret values as encrypted JSON
+	CreatedAt       time.Time          `json:"createdAt" bson:"created_at"`
+	UpdatedAt       time.Time          `json:"updatedAt" bson:"updated_at"`
+	CreatedBy       primitive.ObjectID `json:"createdBy,omitempty" bson:"created_by,omitempty"`
+}
+
+// credentialFields lists the settings of each credential type
+type credentialFields struct {
+	config   []string // required settings
+	optional []string // optional settings
+	secret   []string // required secret values
+}
+
+var credentialTypes = map[CredentialType]credentialFields{
+	// in is "header" (default) or "query"; name is the header or parameter name
+	CredentialTypeAPIKey:      {config: []string{"name"}, optional: []string{"in"}, secret: []string{"key"}},
+	CredentialTypeBasicAuth:   {config: []string{"username"}, secret: []string{"password"}},
+	CredentialTypeBearerToken: {secret: []string{"token"}},
+	// authStyle is "header" (default, HTTP basic auth) or "body"
+	CredentialTypeOAuth2ClientCredentials: {
+		config:   []string{"tokenUrl", "clientId"},
+		optional: []string{"scope", "audience", "authStyle"},
+		secret:   []string{"clientSecret"},
+	},
+}
+
+// CredentialTypes returns the supported credential types
+func CredentialTypes() []CredentialType {
+	return []CredentialType{
+		CredentialTypeAPIKey,
+		CredentialTypeBasicAuth,
+		CredentialTypeBearerToken,
+		CredentialTypeOAuth2ClientCredentials,
+	}
+}
+
+// ValidateCredential checks that the settings and secret values match the
+// credential type
+func ValidateCredential(credType CredentialType, config, secret map[string]string) error {
+	fields, ok := credentialTypes[credType]
+	if !ok {
+		return fmt.Errorf("unknown credential type %q", credType)
+	}
+
+	allowed := make(map[string]bool)
+	for _, key := range append(fields.config, fields.optional...) {
+		allowed[key] = true
+	}
+	for key := range config {
+		if !allowed[key] {
+			return fmt.Errorf("unknown setting %q for %s credentials", key, credType)
+		}
+	}
+	for _, key := range fields.config {
+		if config[key] == "" {
+			return fmt.Errorf("%s credentials require the %q setting", credType, key)
+		}
+	}
+
+	allowedSecret := make(map[string]bool)
+	for _, key := range fields.secret {
+		allowedSecret[key] = true
+		if secret[key] == "" {
+			return fmt.Errorf("%s credentials require the %q secret", credType, key)
+		}
+	}
+	for key := range secret {
+		if !allowedSecret[key] {
+			return fmt.Errorf("unknown secret %q for %s credentials", key, credType)
+		}
+	}
+
+	switch credType {
+	case CredentialTypeAPIKey:
+		if in := config["in"]; in != "" && in != "header" && in != "query" {
+			return fmt.Errorf("api_key credentials must be sent in the header or query")
+		}
+	case CredentialTypeOAuth2ClientCredentials:
+		if style := config["authStyle"]; style != "" && style != "header" && style != "body" {
+			return fmt.Errorf("authStyle must be header or body")
+		}
+	}
+	return nil
+}
+
+// SecretKeysOf returns the sorted names of the secret values
+func SecretKeysOf(secret map[string]string) []string {
+	keys := make([]string, 0, len(secret))
+	for key := range secret {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// CreateCredentialRequest represents a request to create a credential
+type CreateCredentialRequest struct {
+	Name        string            `json:"name" binding:"required"`
+	Description string            `json:"description"`
+	Type        CredentialType    `json:"type" binding:"required"`
+	Config      map[string]string `json:"config"`
+	Secret      map[string]string `json:"secret"`
+}
+
+// UpdateCredentialRequest represents a request to update a credential. The
+// secret values given replace the stored ones; omitted values are kept.
+type UpdateCredentialRequest struct {
+	Name        *string           `json:"name,omitempty"`
+	Description *string           `json:"description,omitempty"`
+	Config      map[string]string `json:"config,omitempty"`
+	Secret      map[string]string `json:"secret,omitempty"`
+}
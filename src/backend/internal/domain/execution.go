@@ -12,15 +12,23 @@ type Execution struct {
 	WorkflowID   primitive.ObjectID `json:"workflowId" bson:"workflow_id"`
 	WorkflowName string             `json:"workflowName" bson:"workflow_name"`
 	Status       ExecutionStatus    `json:"status" bson:"status"`
-	TriggerType  string             `json:"triggerType" bson:"trigger_type"` // webhook, schedule, manual
+	TriggerType  string             `json:"triggerType" bson:"trigger_type"`                    // webhook, schedule, manual
+	Environment  string             `json:"environment,omitempty" bson:"environment,omitempty"` // Project environment the execution ran in
 	Input        map[string]any     `json:"input" bson:"input"`
+	SealedInput  string             `json:"-" bson:"sealed_input,omitempty"` // Encrypted input before redaction, for queued runs, recoveries and replays
 	Output       map[string]any     `json:"output,omitempty" bson:"output,omitempty"`
 	Error        *ExecutionError    `json:"error,omitempty" bson:"error,omitempty"`
 	NodeLogs     []NodeExecutionLog `json:"nodeLogs" bson:"node_logs"`
 	StartedAt    time.Time          `json:"startedAt" bson:"started_at"`
 	CompletedAt  *time.Time         `json:"completedAt,omitempty" bson:"completed_at,omitempty"`
 	Duration     int64              `json:"duration" bson:"duration"` // milliseconds
+	HeartbeatAt  *time.Time         `json:"heartbeatAt,omitempty" bson:"heartbeat_at,omitempty"` // Refreshed while the execution is running
+	Recoveries   int                `json:"recoveries,omitempty" bson:"recoveries,omitempty"`    // Times the execution was re-queued after its runner died
 	Metadata     map[string]any     `json:"metadata,omitempty" bson:"metadata,omitempty"`
+
+	ExpiresAt       *time.Time `json:"expiresAt,omitempty" bson:"expires_at,omitempty"`             // Deleted by the TTL index after this time
+	PayloadsPurged  bool       `json:"payloadsPurged,omitempty" bson:"payloads_purged,omitempty"`   // Node inputs and outputs were dropped by the retention policy
+	CancelRequested bool       `json:"cancelRequested,omitempty" bson:"cancel_requested,omitempty"` // Cancelled by the instance running it on its next heartbeat
 }
 
 type ExecutionStatus string
@@ -40,6 +48,15 @@ type ExecutionError struct {
 	Stack   string `json:"stack,omitempty" bson:"stack,omitempty"`
 }
 
+// Execution error codes
+const (
+	ErrorCodeNodeFailed         = "node_failed"
+	ErrorCodeNodeTimeout        = "node_timeout"
+	ErrorCodeExecutionTimeout   = "execution_timeout"
+	ErrorCodeExecutionCancelled = "execution_cancelled"
+	ErrorCodeExecutionAbandoned = "execution_abandoned"
+)
+
 // NodeExecutionLog represents the execution trace of a single node
 type NodeExecutionLog struct {
 	NodeID      string         `json:"nodeId" bson:"node_id"`
@@ -53,6 +70,20 @@ type NodeExecutionLog struct {
 	CompletedAt *time.Time     `json:"completedAt,omitempty" bson:"completed_at,omitempty"`
 	Duration    int64          `json:"duration" bson:"duration"` // milliseconds
 	Logs        []LogEntry     `json:"logs,omitempty" bson:"logs,omitempty"`
+	Attempts    []NodeAttempt  `json:"attempts,omitempty" bson:"attempts,omitempty"` // Only recorded for nodes with a retry policy
+
+	// Encrypted input and output before redaction, for replays
+	SealedInput  string `json:"-" bson:"sealed_input,omitempty"`
+	SealedOutput string `json:"-" bson:"sealed_output,omitempty"`
+}
+
+// NodeAttempt records a single try of a node with a retry policy
+type NodeAttempt struct {
+	Attempt    int       `json:"attempt" bson:"attempt"`
+	StartedAt  time.Time `json:"startedAt" bson:"started_at"`
+	Duration   int64     `json:"duration" bson:"duration"` // milliseconds
+	StatusCode int       `json:"statusCode,omitempty" bson:"status_code,omitempty"`
+	Error      *string   `json:"error,omitempty" bson:"error,omitempty"`
 }
 
 type LogEntry struct {
@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+// ExecutionEvent reports the progress of a running execution
+type ExecutionEvent struct {
+	Seq         int             `json:"seq"` // Position in the execution's event stream, starting at 1
+	Type        string          `json:"type"`
+	ExecutionID string          `json:"executionId"`
+	WorkflowID  string          `json:"workflowId"`
+	NodeID      string          `json:"nodeId,omitempty"`
+	NodeType    string          `json:"nodeType,omitempty"`
+	NodeLabel   string          `json:"nodeLabel,omitempty"`
+	Status      ExecutionStatus `json:"status"`
+	Output      map[string]any  `json:"output,omitempty"`
+	Error       *ExecutionError `json:"error,omitempty"`
+	Duration    int64           `json:"duration,omitempty"` // milliseconds
+	Timestamp   time.Time       `json:"timestamp"`
+}
+
+// Execution event types
+const (
+	EventExecutionStarted   = "execution.started"
+	EventExecutionCompleted = "execution.completed"
+	EventExecutionFailed    = "execution.failed"
+	EventExecutionCancelled = "execution.cancelled"
+	EventNodeStarted        = "node.started"
+	EventNodeCompleted      = "node.completed"
+	EventNodeFailed         = "node.failed"
+)
+
+// IsFinal reports whether the event ends the execution's stream
+func (e ExecutionEvent) IsFinal() bool {
+	switch e.Type {
+	case EventExecutionCompleted, EventExecutionFailed, EventExecutionCancelled:
+		return true
+	}
+	return false
+}
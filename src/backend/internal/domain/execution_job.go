@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ExecutionJob is a queued request to run a pending execution asynchronously
+type ExecutionJob struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	ExecutionID primitive.ObjectID `json:"executionId" bson:"execution_id"`
+	WorkflowID  primitive.ObjectID `json:"workflowId" bson:"workflow_id"`
+	TriggerPath string             `json:"triggerPath,omitempty" bson:"trigger_path,omitempty"`
+	Status      ExecutionJobStatus `json:"status" bson:"status"`
+	WorkerID    string             `json:"workerId,omitempty" bson:"worker_id,omitempty"`
+	Attempts    int                `json:"attempts" bson:"attempts"`
+	CreatedAt   time.Time          `json:"createdAt" bson:"created_at"`
+	LockedAt    *time.Time         `json:"lockedAt,omitempty" bson:"locked_at,omitempty"`
+}
+
+type ExecutionJobStatus string
+
+const (
+	ExecutionJobStatusQueued  ExecutionJobStatus = "queued"
+	ExecutionJobStatusRunning ExecutionJobStatus = "running"
+)
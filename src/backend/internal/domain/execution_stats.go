@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ExecutionStats summarizes the executions of one or more workflows started
+// in a time range
+type ExecutionStats struct {
+	From     time.Time `json:"from" bson:"-"`
+	To       time.Time `json:"to" bson:"-"`
+	Interval string    `json:"interval" bson:"-"` // Length of each bucket
+
+	ExecutionCounts `bson:",inline"`
+	SuccessRate     float64      `json:"successRate" bson:"-"` // Completed out of finished executions, 0 to 1
+	Latency         LatencyStats `json:"latency" bson:"latency"`
+
+	Buckets         []ExecutionStatsBucket `json:"buckets" bson:"-"`
+	TopFailingNodes []FailingNodeStats     `json:"topFailingNodes" bson:"-"`
+	CommonErrors    []ErrorStats           `json:"commonErrors" bson:"-"`
+}
+
+// ExecutionCounts counts executions by status
+type ExecutionCounts struct {
+	Total     int64 `json:"total" bson:"total"`
+	Pending   int64 `json:"pending" bson:"pending"`
+	Running   int64 `json:"running" bson:"running"`
+	Completed int64 `json:"completed" bson:"completed"`
+	Failed    int64 `json:"failed" bson:"failed"`
+	Cancelled int64 `json:"cancelled" bson:"cancelled"`
+}
+
+// LatencyStats describes the durations of completed and failed executions,
+// in milliseconds. Percentiles are approximate.
+type LatencyStats struct {
+	P50 float64 `json:"p50" bson:"p50"`
+	P95 float64 `json:"p95" bson:"p95"`
+	P99 float64 `json:"p99" bson:"p99"`
+	Avg float64 `json:"avg" bson:"avg"`
+	Max float64 `json:"max" bson:"max"`
+}
+
+// ExecutionStatsBucket summarizes the executions started in one interval
+type ExecutionStatsBucket struct {
+	Start           time.Time `json:"start" bson:"-"`
+	ExecutionCounts `bson:",inline"`
+	Latency         LatencyStats `json:"latency" bson:"latency"`
+}
+
+// FailingNodeStats counts the failures of a node
+type FailingNodeStats struct {
+	WorkflowID   primitive.ObjectID `json:"workflowId" bson:"workflow_id"`
+	WorkflowName string             `json:"workflowName" bson:"workflow_name"`
+	NodeID       string             `json:"nodeId" bson:"node_id"`
+	NodeLabel    string             `json:"nodeLabel" bson:"node_label"`
+	NodeType     string             `json:"nodeType" bson:"node_type"`
+	Failures     int64              `json:"failures" bson:"failures"`
+	LastError    string             `json:"lastError,omitempty" bson:"last_error,omitempty"`
+	LastFailedAt time.Time          `json:"lastFailedAt" bson:"last_failed_at"`
+}
+
+// ErrorStats counts the failed executions with the same error message
+type ErrorStats struct {
+	Message     string               `json:"message" bson:"message"`
+	Code        string               `json:"code,omitempty" bson:"code,omitempty"`
+	NodeID      string               `json:"nodeId,omitempty" bson:"node_id,omitempty"` // Node of the latest occurrence
+	Count       int64                `json:"count" bson:"count"`
+	LastSeenAt  time.Time            `json:"lastSeenAt" bson:"last_seen_at"`
+	WorkflowIDs []primitive.ObjectID `json:"workflowIds" bson:"workflow_ids"`
+}
@@ -32,15 +32,17 @@ type PortDefinition struct {
 
 // Built-in node type constants
 const (
-	NodeTypeTrigger   = "trigger"
-	NodeTypeTransform = "transform"
-	NodeTypeHTTP      = "http"
-	NodeTypeCondition = "condition"
-	NodeTypeLoop      = "loop"
-	NodeTypeCode      = "code"
-	NodeTypeDelay     = "delay"
-	NodeTypeEmail     = "email"
-	NodeTypeWebhook   = "webhook"
+	NodeTypeTrigger         = "trigger"
+	NodeTypeTransform       = "transform"
+	NodeTypeHTTP            = "http"
+	NodeTypeCondition       = "condition"
+	NodeTypeLoop            = "loop"
+	NodeTypeCode            = "code"
+	NodeTypeDelay           = "delay"
+	NodeTypeEmail           = "email"
+	NodeTypeWebhook         = "webhook"
+	NodeTypeResponse        = "response"
+	NodeTypeExecuteWorkflow = "execute_workflow"
 )
 
 // Node categories
@@ -73,6 +75,7 @@ func GetBuiltInNodeTypes() []NodeType {
 					"triggerType": map[string]any{"type": "string", "enum": []string{"webhook", "schedule", "manual"}},
 					"webhookPath": map[string]any{"type": "string"},
 					"schedule":    map[string]any{"type": "string"},
+					"timezone":    map[string]any{"type": "string"},
 				},
 			},
 		},
@@ -164,9 +167,12 @@ func GetBuiltInNodeTypes() []NodeType {
 			ConfigSchema: map[string]any{
 				"type": "object",
 				"properties": map[string]any{
-					"loopType":      map[string]any{"type": "string", "enum": []string{"forEach", "while", "for"}},
-					"loopArrayPath": map[string]any{"type": "string"},
-					"loopCondition": map[string]any{"type": "string"},
+					"loopType":          map[string]any{"type": "string", "enum": []string{"forEach", "while", "for"}},
+					"loopArrayPath":     map[string]any{"type": "string"},
+					"loopCondition":     map[string]any{"type": "string"},
+					"loopCount":         map[string]any{"type": "number"},
+					"loopConcurrency":   map[string]any{"type": "number", "default": 1},
+					"loopMaxIterations": map[string]any{"type": "number", "default": 1000},
 				},
 			},
 		},
@@ -214,7 +220,7 @@ func GetBuiltInNodeTypes() []NodeType {
 		},
 		{
 			Name:        "Response",
-			Type:        "response",
+			Type:        NodeTypeResponse,
 			Category:    CategoryAction,
 			Description: "Send response back to the caller. Use after Transform to return the mapped data.",
 			Icon:        "send",
@@ -232,5 +238,29 @@ func GetBuiltInNodeTypes() []NodeType {
 				},
 			},
 		},
+		{
+			Name:        "Execute Workflow",
+			Type:        NodeTypeExecuteWorkflow,
+			Category:    CategoryAction,
+			Description: "Run another workflow and use its output",
+			Icon:        "workflow",
+			Color:       "#0EA5E9",
+			IsBuiltIn:   true,
+			Inputs: []PortDefinition{
+				{Name: "input", Type: "any", Required: false, Description: "Input for the child workflow"},
+			},
+			Outputs: []PortDefinition{
+				{Name: "output", Type: "any", Required: true, Description: "Output of the child workflow"},
+			},
+			ConfigSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"subWorkflowId":    map[string]any{"type": "string"},
+					"subWorkflowPath":  map[string]any{"type": "string"},
+					"subWorkflowAsync": map[string]any{"type": "boolean", "default": false},
+					"mappingRules":     map[string]any{"type": "array"},
+				},
+			},
+		},
 	}
 }
@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"fmt"
+	"regexp"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -19,6 +21,63 @@ type Project struct {
 	CreatedAt   time.Time          `json:"createdAt" bson:"created_at"`
 	UpdatedAt   time.Time          `json:"updatedAt" bson:"updated_at"`
 	CreatedBy   string             `json:"createdBy" bson:"created_by"`
+
+	Environments       []Environment    `json:"environments,omitempty" bson:"environments,omitempty"`
+	DefaultEnvironment string           `json:"defaultEnvironment,omitempty" bson:"default_environment,omitempty"` // Used by triggers not bound to an environment
+	Retention          *RetentionPolicy `json:"retention,omitempty" bson:"retention,omitempty"`                    // Applies to workflows without their own policy
+}
+
+// Environment is a named deployment target of a project, such as dev,
+// staging or prod. Runs in an environment see its variables as $vars and
+// use its credential bindings, so the same workflow can call a sandbox API
+// in staging and the real one in prod.
+type Environment struct {
+	Name        string            `json:"name" bson:"name"`
+	Description string            `json:"description,omitempty" bson:"description,omitempty"`
+	Variables   map[string]any    `json:"variables,omitempty" bson:"variables,omitempty"`     // Override the workflow variables of the same name
+	Credentials map[string]string `json:"credentials,omitempty" bson:"credentials,omitempty"` // Binding name or credential ID used by nodes -> credential ID
+}
+
+// Environment returns the environment with the given name, or nil
+func (p *Project) Environment(name string) *Environment {
+	for i := range p.Environments {
+		if p.Environments[i].Name == name {
+			return &p.Environments[i]
+		}
+	}
+	return nil
+}
+
+var environmentName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ValidateEnvironments checks that environment names are unique slugs, that
+// credential bindings hold credential IDs and that the default exists
+func ValidateEnvironments(environments []Environment, defaultEnvironment string) error {
+	seen := make(map[string]bool)
+	for _, env := range environments {
+		if !environmentName.MatchString(env.Name) {
+			return fmt.Errorf("environment name %q must be lowercase letters, digits, - or _", env.Name)
+		}
+		if seen[env.Name] {
+			return fmt.Errorf("environment %q is defined twice", env.Name)
+		}
+		seen[env.Name] = true
+		for binding, credentialID := range env.Credentials {
+			if !primitive.IsValidObjectID(credentialID) {
+				return fmt.Errorf("credential binding %q of environment %q must be a credential ID", binding, env.Name)
+			}
+		}
+	}
+	if defaultEnvironment != "" && !seen[defaultEnvironment] {
+		return fmt.Errorf("default environment %q is not defined", defaultEnvironment)
+	}
+	return nil
+}
+
+// UpdateEnvironmentsRequest replaces the environments of a project
+type UpdateEnvironmentsRequest struct {
+	Environments       []Environment `json:"environments"`
+	DefaultEnvironment string        `json:"defaultEnvironment"`
 }
 
 type ProjectStatus string
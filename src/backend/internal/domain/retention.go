@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// RetentionPolicy limits how long finished executions are stored. Workflows
+// can set it in their settings and projects for all of their workflows;
+// unset fields fall back to the project, then to the server defaults.
+type RetentionPolicy struct {
+	MaxAgeDays             int   `json:"maxAgeDays,omitempty" bson:"max_age_days,omitempty"`                         // Delete executions started this many days ago
+	MaxCount               int   `json:"maxCount,omitempty" bson:"max_count,omitempty"`                              // Keep only this many of the latest finished executions per workflow
+	KeepSuccessfulPayloads *bool `json:"keepSuccessfulPayloads,omitempty" bson:"keep_successful_payloads,omitempty"` // Keep node inputs and outputs of completed executions (default true)
+}
+
+// Validate checks that the limits are not negative
+func (p *RetentionPolicy) Validate() error {
+	if p == nil {
+		return nil
+	}
+	if p.MaxAgeDays < 0 {
+		return fmt.Errorf("retention maxAgeDays cannot be negative")
+	}
+	if p.MaxCount < 0 {
+		return fmt.Errorf("retention maxCount cannot be negative")
+	}
+	return nil
+}
+
+// MaxAge returns the maximum age as a duration, or 0 if executions do not
+// expire by age
+func (p RetentionPolicy) MaxAge() time.Duration {
+	return time.Duration(p.MaxAgeDays) * 24 * time.Hour
+}
+
+// KeepsSuccessfulPayloads reports whether completed executions keep the
+// inputs and outputs of their nodes
+func (p RetentionPolicy) KeepsSuccessfulPayloads() bool {
+	return p.KeepSuccessfulPayloads == nil || *p.KeepSuccessfulPayloads
+}
+
+// ResolveRetention merges retention policies from the most specific to the
+// least specific; the first one that sets a field wins
+func ResolveRetention(policies ...*RetentionPolicy) RetentionPolicy {
+	var resolved RetentionPolicy
+	for _, p := range policies {
+		if p == nil {
+			continue
+		}
+		if resolved.MaxAgeDays == 0 {
+			resolved.MaxAgeDays = p.MaxAgeDays
+		}
+		if resolved.MaxCount == 0 {
+			resolved.MaxCount = p.MaxCount
+		}
+		if resolved.KeepSuccessfulPayloads == nil {
+			resolved.KeepSuccessfulPayloads = p.KeepSuccessfulPayloads
+		}
+	}
+	return resolved
+}
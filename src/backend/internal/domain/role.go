@@ -78,6 +78,12 @@ const (
 	PermissionInvitationEdit   Permission = "invitations:edit"
 	PermissionInvitationDelete Permission = "invitations:delete"
 
+	// Credential permissions
+	PermissionCredentialView   Permission = "credentials:view"
+	PermissionCredentialCreate Permission = "credentials:create"
+	PermissionCredentialEdit   Permission = "credentials:edit"
+	PermissionCredentialDelete Permission = "credentials:delete"
+
 	// Settings permissions
 	PermissionSettingsView Permission = "settings:view"
 	PermissionSettingsEdit Permission = "settings:edit"
@@ -97,6 +103,7 @@ func AllPermissions() []Permission {
 		PermissionUserView, PermissionUserCreate, PermissionUserEdit, PermissionUserDelete,
 		PermissionRoleView, PermissionRoleCreate, PermissionRoleEdit, PermissionRoleDelete,
 		PermissionInvitationView, PermissionInvitationCreate, PermissionInvitationEdit, PermissionInvitationDelete,
+		PermissionCredentialView, PermissionCredentialCreate, PermissionCredentialEdit, PermissionCredentialDelete,
 		PermissionSettingsView, PermissionSettingsEdit,
 	}
 }
@@ -121,6 +128,7 @@ func EditorPermissions() []Permission {
 		PermissionNodeSchemaView, PermissionNodeSchemaCreate, PermissionNodeSchemaEdit,
 		PermissionVersionView, PermissionVersionCreate, PermissionVersionEdit,
 		PermissionProjectView, PermissionProjectCreate, PermissionProjectEdit,
+		PermissionCredentialView, PermissionCredentialCreate, PermissionCredentialEdit,
 	}
 }
 
@@ -38,7 +38,8 @@ type SchemaField struct {
 	Format      string        `json:"format,omitempty" bson:"format,omitempty"` // date-time, email, uri, etc.
 	Enum        []any         `json:"enum,omitempty" bson:"enum,omitempty"`
 	Default     any           `json:"default,omitempty" bson:"default,omitempty"`
-	Children    []SchemaField `json:"children,omitempty" bson:"children,omitempty"` // for nested objects
+	Children    []SchemaField `json:"children,omitempty" bson:"children,omitempty"`   // for nested objects
+	Sensitive   bool          `json:"sensitive,omitempty" bson:"sensitive,omitempty"` // Redacted in the executions of workflows using the schema
 }
 
 // GetPredefinedSchemas returns all predefined standard schemas
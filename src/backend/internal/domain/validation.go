@@ -0,0 +1,21 @@
+package domain
+
+// ValidationIssue is a problem found while statically checking a workflow
+type ValidationIssue struct {
+	NodeID  string `json:"nodeId,omitempty"` // Node the issue belongs to; empty for workflow-wide issues
+	EdgeID  string `json:"edgeId,omitempty"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Validation issue codes
+const (
+	ValidationNoTrigger          = "no_trigger"
+	ValidationUnknownNodeType    = "unknown_node_type"
+	ValidationInvalidConfig      = "invalid_config"
+	ValidationMissingNode        = "missing_node"
+	ValidationMissingPort        = "missing_port"
+	ValidationCycle              = "cycle"
+	ValidationUnreachable        = "unreachable"
+	ValidationUnknownEnvironment = "unknown_environment"
+)
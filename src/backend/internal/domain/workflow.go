@@ -31,8 +31,19 @@ type WorkflowSettings struct {
 	AutoSaveEnabled bool              `json:"autoSaveEnabled" bson:"auto_save_enabled"`
 	WebhookPath     string            `json:"webhookPath,omitempty" bson:"webhook_path,omitempty"`
 	CustomHeaders   map[string]string `json:"customHeaders,omitempty" bson:"custom_headers,omitempty"`
+	AsyncExecution  bool              `json:"asyncExecution,omitempty" bson:"async_execution,omitempty"` // Webhooks return 202 and run in the background
+	MaxDurationMs   int               `json:"maxDurationMs,omitempty" bson:"max_duration_ms,omitempty"`  // Upper bound for a whole execution
+	Idempotent      bool              `json:"idempotent,omitempty" bson:"idempotent,omitempty"`          // Safe to re-run from the start after a crash
+	Retention       *RetentionPolicy  `json:"retention,omitempty" bson:"retention,omitempty"`            // Overrides the project's retention policy
+	RedactPaths     []string          `json:"redactPaths,omitempty" bson:"redact_paths,omitempty"`       // Field paths hidden in stored and returned execution data
 }
 
+// Merge modes for nodes joining several upstream branches
+const (
+	MergeModeMerge  = "merge"  // deep merge all branch outputs into one object
+	MergeModeByNode = "byNode" // key each branch output by its source node ID
+)
+
 type WorkflowStatus string
 
 const (
@@ -67,13 +78,18 @@ type NodePort struct {
 // NodeData contains type-specific configuration for each node
 type NodeData struct {
 	// Common fields
-	Description string `json:"description,omitempty" bson:"description,omitempty"`
+	Description string       `json:"description,omitempty" bson:"description,omitempty"`
+	MergeMode   string       `json:"mergeMode,omitempty" bson:"merge_mode,omitempty"` // merge (default), byNode - how inputs from several upstream branches are combined
+	Retry       *RetryPolicy `json:"retry,omitempty" bson:"retry,omitempty"`          // Retry failed executions of this node
+	TimeoutMs   int          `json:"timeoutMs,omitempty" bson:"timeout_ms,omitempty"` // Fail the node if a single attempt runs longer than this
 
 	// Trigger node specific
 	TriggerType   string `json:"triggerType,omitempty" bson:"trigger_type,omitempty"` // webhook, schedule, manual
 	WebhookPath   string `json:"webhookPath,omitempty" bson:"webhook_path,omitempty"`
 	WebhookMethod string `json:"webhookMethod,omitempty" bson:"webhook_method,omitempty"` // GET, POST, PUT, DELETE
 	Schedule      string `json:"schedule,omitempty" bson:"schedule,omitempty"`            // cron expression
+	Timezone      string `json:"timezone,omitempty" bson:"timezone,omitempty"`            // IANA time zone for the schedule (default UTC)
+	Environment   string `json:"environment,omitempty" bson:"environment,omitempty"`      // Project environment runs started by this trigger use
 
 	// Transform node specific
 	SourceSchemaID string        `json:"sourceSchemaId,omitempty" bson:"source_schema_id,omitempty"`
@@ -81,18 +97,22 @@ type NodeData struct {
 	MappingRules   []MappingRule `json:"mappingRules,omitempty" bson:"mapping_rules,omitempty"`
 
 	// HTTP node specific
-	HTTPMethod  string            `json:"httpMethod,omitempty" bson:"http_method,omitempty"`
-	HTTPURL     string            `json:"httpUrl,omitempty" bson:"http_url,omitempty"`
-	HTTPHeaders map[string]string `json:"httpHeaders,omitempty" bson:"http_headers,omitempty"`
-	HTTPBody    string            `json:"httpBody,omitempty" bson:"http_body,omitempty"`
+	HTTPMethod   string            `json:"httpMethod,omitempty" bson:"http_method,omitempty"`
+	HTTPURL      string            `json:"httpUrl,omitempty" bson:"http_url,omitempty"`
+	HTTPHeaders  map[string]string `json:"httpHeaders,omitempty" bson:"http_headers,omitempty"`
+	HTTPBody     string            `json:"httpBody,omitempty" bson:"http_body,omitempty"`
+	CredentialID string            `json:"credentialId,omitempty" bson:"credential_id,omitempty"` // Stored credential that authorizes the request
 
 	// Condition node specific
 	Conditions []Condition `json:"conditions,omitempty" bson:"conditions,omitempty"`
 
 	// Loop node specific
-	LoopType      string `json:"loopType,omitempty" bson:"loop_type,omitempty"` // forEach, while, for
-	LoopArrayPath string `json:"loopArrayPath,omitempty" bson:"loop_array_path,omitempty"`
-	LoopCondition string `json:"loopCondition,omitempty" bson:"loop_condition,omitempty"`
+	LoopType          string `json:"loopType,omitempty" bson:"loop_type,omitempty"` // forEach, while, for
+	LoopArrayPath     string `json:"loopArrayPath,omitempty" bson:"loop_array_path,omitempty"`
+	LoopCondition     string `json:"loopCondition,omitempty" bson:"loop_condition,omitempty"`          // while: keep iterating while true, e.g. "page < 10"
+	LoopCount         int    `json:"loopCount,omitempty" bson:"loop_count,omitempty"`                  // for: number of iterations
+	LoopConcurrency   int    `json:"loopConcurrency,omitempty" bson:"loop_concurrency,omitempty"`      // forEach/for: iterations run in parallel (default 1)
+	LoopMaxIterations int    `json:"loopMaxIterations,omitempty" bson:"loop_max_iterations,omitempty"` // safety cap (default 1000)
 
 	// Code node specific (custom JavaScript/expression)
 	Code string `json:"code,omitempty" bson:"code,omitempty"`
@@ -100,10 +120,32 @@ type NodeData struct {
 	// Response node specific
 	ResponseConfig *ResponseConfig `json:"responseConfig,omitempty" bson:"response_config,omitempty"`
 
+	// Execute workflow node specific (input is mapped with MappingRules)
+	SubWorkflowID    string `json:"subWorkflowId,omitempty" bson:"sub_workflow_id,omitempty"`
+	SubWorkflowPath  string `json:"subWorkflowPath,omitempty" bson:"sub_workflow_path,omitempty"`   // endpoint path, used when no ID is set
+	SubWorkflowAsync bool   `json:"subWorkflowAsync,omitempty" bson:"sub_workflow_async,omitempty"` // fire and forget instead of waiting for the output
+
 	// Custom node specific
 	CustomConfig map[string]any `json:"customConfig,omitempty" bson:"custom_config,omitempty"`
 }
 
+// RetryPolicy controls how a failing node is retried
+type RetryPolicy struct {
+	MaxAttempts    int      `json:"maxAttempts" bson:"max_attempts"`                            // Total attempts including the first one
+	Backoff        string   `json:"backoff,omitempty" bson:"backoff,omitempty"`                 // fixed (default), linear, exponential
+	InitialDelayMs int      `json:"initialDelayMs,omitempty" bson:"initial_delay_ms,omitempty"` // Delay before the first retry (default 1000)
+	MaxDelayMs     int      `json:"maxDelayMs,omitempty" bson:"max_delay_ms,omitempty"`         // Upper bound for the delay between attempts
+	RetryOnStatus  []int    `json:"retryOnStatus,omitempty" bson:"retry_on_status,omitempty"`   // HTTP statuses to retry (default 408, 429, 500, 502, 503, 504)
+	RetryOnErrors  []string `json:"retryOnErrors,omitempty" bson:"retry_on_errors,omitempty"`   // Error message fragments to retry; empty retries every error
+}
+
+// Retry backoff strategies
+const (
+	BackoffFixed       = "fixed"
+	BackoffLinear      = "linear"
+	BackoffExponential = "exponential"
+)
+
 // ResponseConfig contains configuration for response node
 type ResponseConfig struct {
 	StatusCode       int               `json:"statusCode" bson:"status_code"`
@@ -153,7 +195,7 @@ type MappingRule struct {
 type Condition struct {
 	ID       string `json:"id" bson:"id"`
 	Field    string `json:"field" bson:"field"`
-	Operator string `json:"operator" bson:"operator"` // eq, neq, gt, gte, lt, lte, contains, regex
+	Operator string `json:"operator" bson:"operator"` // eq, neq, gt, gte, lt, lte, contains, regex, expression
 	Value    any    `json:"value" bson:"value"`
 	OutputID string `json:"outputId" bson:"output_id"` // which output port to use if condition matches
 }
@@ -170,11 +212,12 @@ type Edge struct {
 
 // EndpointConfig for auto-generated webhook endpoints
 type EndpointConfig struct {
-	Path       string            `json:"path" bson:"path"`
-	Method     string            `json:"method" bson:"method"`      // GET, POST, PUT, DELETE
-	AuthType   string            `json:"authType" bson:"auth_type"` // none, apiKey, jwt
-	APIKey     string            `json:"apiKey,omitempty" bson:"api_key,omitempty"`
-	Headers    map[string]string `json:"headers,omitempty" bson:"headers,omitempty"`      // Custom headers to require
-	RateLimit  int               `json:"rateLimit,omitempty" bson:"rate_limit,omitempty"` // requests per minute
-	AllowedIPs []string          `json:"allowedIPs,omitempty" bson:"allowed_ips,omitempty"`
+	Path        string            `json:"path" bson:"path"`
+	Method      string            `json:"method" bson:"method"`      // GET, POST, PUT, DELETE
+	AuthType    string            `json:"authType" bson:"auth_type"` // none, apiKey, jwt
+	APIKey      string            `json:"apiKey,omitempty" bson:"api_key,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty" bson:"headers,omitempty"`      // Custom headers to require
+	RateLimit   int               `json:"rateLimit,omitempty" bson:"rate_limit,omitempty"` // requests per minute
+	AllowedIPs  []string          `json:"allowedIPs,omitempty" bson:"allowed_ips,omitempty"`
+	Environment string            `json:"environment,omitempty" bson:"environment,omitempty"` // Project environment requests to this endpoint run in
 }
@@ -0,0 +1,116 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nodetl/nodetl/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	// errExecutionCancelled is the cancellation cause when a user cancels a run
+	errExecutionCancelled = errors.New("execution was cancelled")
+
+	// ErrExecutionNotFound is returned when cancelling an unknown execution
+	ErrExecutionNotFound = errors.New("execution not found")
+	// ErrExecutionFinished is returned when cancelling an execution that already ended
+	ErrExecutionFinished = errors.New("execution has already finished")
+)
+
+// runRegistry tracks the executions running in this process so they can be cancelled
+type runRegistry struct {
+	mu   sync.Mutex
+	runs map[primitive.ObjectID]context.CancelCauseFunc
+}
+
+func newRunRegistry() *runRegistry {
+	return &runRegistry{runs: make(map[primitive.ObjectID]context.CancelCauseFunc)}
+}
+
+func (r *runRegistry) add(id primitive.ObjectID, cancel context.CancelCauseFunc) {
+	r.mu.Lock()
+	r.runs[id] = cancel
+	r.mu.Unlock()
+}
+
+func (r *runRegistry) remove(id primitive.ObjectID) {
+	r.mu.Lock()
+	delete(r.runs, id)
+	r.mu.Unlock()
+}
+
+// cancel stops a registered run. It reports whether the run was found.
+func (r *runRegistry) cancel(id primitive.ObjectID) bool {
+	r.mu.Lock()
+	cancel, ok := r.runs[id]
+	r.mu.Unlock()
+	if ok {
+		cancel(errExecutionCancelled)
+	}
+	return ok
+}
+
+// Cancel stops an execution. Running executions are interrupted and recorded
+// as cancelled with the node logs gathered so far; those running on another
+// instance are flagged and stopped there on their next heartbeat. Queued
+// executions are cancelled before a worker picks them up.
+func (e *FlowExecutor) Cancel(ctx context.Context, executionID primitive.ObjectID) error {
+	if e.running.cancel(executionID) {
+		return nil
+	}
+
+	execution, err := e.executionRepo.GetByID(ctx, executionID)
+	if err != nil {
+		return fmt.Errorf("failed to get execution: %w", err)
+	}
+	if execution == nil {
+		return ErrExecutionNotFound
+	}
+
+	if execution.Status == domain.ExecutionStatusPending {
+		now := time.Now()
+		execution.Status = domain.ExecutionStatusCancelled
+		execution.Error = &domain.ExecutionError{
+			Message: errExecutionCancelled.Error(),
+			Code:    domain.ErrorCodeExecutionCancelled,
+		}
+		execution.CompletedAt = &now
+		stored, err := e.executionRepo.ReplaceIfStatus(ctx, execution, domain.ExecutionStatusPending)
+		if err != nil {
+			return fmt.Errorf("failed to update execution: %w", err)
+		}
+		if stored {
+			e.publishFinished(execution)
+			return nil
+		}
+
+		// A worker started it since it was read
+		execution, err = e.executionRepo.GetByID(ctx, executionID)
+		if err != nil {
+			return fmt.Errorf("failed to get execution: %w", err)
+		}
+		if execution == nil {
+			return ErrExecutionNotFound
+		}
+	}
+
+	if execution.Status != domain.ExecutionStatusRunning {
+		return ErrExecutionFinished
+	}
+	// The run may have started here between the registry lookup and the read
+	if e.running.cancel(executionID) {
+		return nil
+	}
+	requested, err := e.executionRepo.RequestCancel(ctx, executionID)
+	if err != nil {
+		return fmt.Errorf("failed to update execution: %w", err)
+	}
+	if !requested {
+		return ErrExecutionFinished
+	}
+	return nil
+}
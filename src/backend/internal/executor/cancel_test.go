@@ -0,0 +1,92 @@
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nodetl/nodetl/internal/domain"
+)
+
+func TestCancelWinsOverWorkerStartingTheRun(t *testing.T) {
+	ctx := context.Background()
+	workflow := authorizationWorkflow()
+	e, executions := newTestExecutor(workflow)
+
+	pool := NewWorkerPool(e, &memJobs{}, 1, 0)
+	queued, err := pool.Submit(ctx, &ExecuteRequest{WorkflowID: workflow.ID, Input: authorizedRequest()})
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	id := mustObjectID(t, queued.ExecutionID)
+
+	// The user cancels after the worker read the execution as pending
+	executions.afterGet = func() {
+		if err := e.Cancel(ctx, id); err != nil {
+			t.Errorf("Cancel() error = %v", err)
+		}
+	}
+	if _, err := e.RunPending(ctx, id, ""); err == nil {
+		t.Fatal("RunPending() started a cancelled execution")
+	}
+
+	execution, _ := executions.GetByID(ctx, id)
+	if execution.Status != domain.ExecutionStatusCancelled {
+		t.Fatalf("status = %s, want cancelled", execution.Status)
+	}
+	if len(execution.NodeLogs) > 0 {
+		t.Fatalf("nodes ran after cancellation: %+v", execution.NodeLogs)
+	}
+}
+
+func TestCancelStopsRunOnAnotherInstance(t *testing.T) {
+	defer func(interval time.Duration) { heartbeatInterval = interval }(heartbeatInterval)
+	heartbeatInterval = 10 * time.Millisecond
+
+	ctx := context.Background()
+	workflow := &domain.Workflow{
+		Name: "slow",
+		Nodes: []domain.Node{
+			{ID: "trigger", Type: domain.NodeTypeTrigger},
+			{ID: "wait", Type: domain.NodeTypeDelay, Data: domain.NodeData{
+				CustomConfig: map[string]any{"duration": float64(10000)},
+			}},
+		},
+		Edges: []domain.Edge{{ID: "e1", Source: "trigger", Target: "wait"}},
+	}
+	running, executions := newTestExecutor(workflow)
+	other := *running
+	other.running = newRunRegistry()
+
+	pool := NewWorkerPool(running, &memJobs{}, 1, 0)
+	queued, err := pool.Submit(ctx, &ExecuteRequest{WorkflowID: workflow.ID})
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	id := mustObjectID(t, queued.ExecutionID)
+
+	done := make(chan *ExecuteResult, 1)
+	go func() {
+		result, _ := running.RunPending(ctx, id, "")
+		done <- result
+	}()
+	for {
+		execution, _ := executions.GetByID(ctx, id)
+		if execution.Status == domain.ExecutionStatusRunning {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := other.Cancel(ctx, id); err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+	select {
+	case result := <-done:
+		if result == nil || result.Status != domain.ExecutionStatusCancelled {
+			t.Fatalf("RunPending() = %+v, want cancelled", result)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the run was not cancelled")
+	}
+}
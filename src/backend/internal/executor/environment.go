@@ -0,0 +1,133 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/nodetl/nodetl/internal/domain"
+	"github.com/nodetl/nodetl/internal/node"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrUnknownEnvironment is returned when a run asks for an environment its
+// workflow's project does not define
+var ErrUnknownEnvironment = errors.New("unknown environment")
+
+// selectEnvironment picks the project environment a new execution runs in:
+// the one asked for in the request, the parent's for child workflows whose
+// project defines it, the one the endpoint or trigger is bound to, or the
+// project default. Workflows without environments run in none.
+func (e *FlowExecutor) selectEnvironment(project *domain.Project, workflow *domain.Workflow, req *ExecuteRequest) (string, error) {
+	name := req.Environment
+	if name == "" && req.parentEnvironment != "" && project != nil && project.Environment(req.parentEnvironment) != nil {
+		name = req.parentEnvironment
+	}
+	if name == "" {
+		name = e.boundEnvironment(workflow, req)
+	}
+	if name == "" && project != nil {
+		name = project.DefaultEnvironment
+	}
+
+	if name != "" && (project == nil || project.Environment(name) == nil) {
+		return "", fmt.Errorf("%w %q: it is not defined in the workflow's project", ErrUnknownEnvironment, name)
+	}
+	return name, nil
+}
+
+// boundEnvironment returns the environment of the endpoint or trigger node
+// that starts the run
+func (e *FlowExecutor) boundEnvironment(workflow *domain.Workflow, req *ExecuteRequest) string {
+	if workflow.Endpoint != nil && req.TriggerPath != "" && workflow.Endpoint.Path == req.TriggerPath && workflow.Endpoint.Environment != "" {
+		return workflow.Endpoint.Environment
+	}
+
+	var trigger *domain.Node
+	switch {
+	case req.TriggerNodeID != "":
+		trigger = e.findTriggerNodeByID(workflow.Nodes, req.TriggerNodeID)
+	case req.TriggerPath != "":
+		trigger = e.findTriggerNodeByPath(workflow.Nodes, req.TriggerPath)
+	default:
+		trigger = e.findTriggerNode(workflow.Nodes)
+	}
+	if trigger == nil {
+		return ""
+	}
+	return trigger.Data.Environment
+}
+
+// loadEnvironment returns the named environment of the workflow's project,
+// or nil if the execution has no environment
+func loadEnvironment(project *domain.Project, name string) (*domain.Environment, error) {
+	if name == "" {
+		return nil, nil
+	}
+	if project != nil {
+		if env := project.Environment(name); env != nil {
+			return env, nil
+		}
+	}
+	return nil, fmt.Errorf("%w %q: it is no longer defined in the workflow's project", ErrUnknownEnvironment, name)
+}
+
+// workflowProject loads the project of a workflow, or nil if it has none
+func (e *FlowExecutor) workflowProject(ctx context.Context, workflow *domain.Workflow) (*domain.Project, error) {
+	if e.projectRepo == nil || workflow.ProjectID == "" {
+		return nil, nil
+	}
+	project, err := e.projectRepo.GetByID(ctx, workflow.ProjectID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+	return project, nil
+}
+
+// environmentVariables returns the workflow variables overridden by those of
+// the environment
+func environmentVariables(workflow *domain.Workflow, env *domain.Environment) map[string]any {
+	if env == nil || len(env.Variables) == 0 {
+		return workflow.Variables
+	}
+	vars := make(map[string]any, len(workflow.Variables)+len(env.Variables))
+	for k, v := range workflow.Variables {
+		vars[k] = v
+	}
+	for k, v := range env.Variables {
+		vars[k] = v
+	}
+	return vars
+}
+
+// environmentCredentials resolves the credential references of nodes through
+// the bindings of an environment. A node's credentialId may name a binding,
+// or be a credential ID the environment replaces with another one.
+type environmentCredentials struct {
+	bindings map[string]string
+	next     node.CredentialResolver
+}
+
+// withEnvironment wraps a resolver with the credential bindings of an
+// environment
+func withEnvironment(resolver node.CredentialResolver, env *domain.Environment) node.CredentialResolver {
+	if env == nil || len(env.Credentials) == 0 {
+		return resolver
+	}
+	return &environmentCredentials{bindings: env.Credentials, next: resolver}
+}
+
+// Authorize implements node.CredentialResolver
+func (c *environmentCredentials) Authorize(ctx context.Context, credentialID string, req *http.Request) error {
+	if bound, ok := c.bindings[credentialID]; ok {
+		credentialID = bound
+	}
+	if c.next == nil {
+		return fmt.Errorf("credentials are not available")
+	}
+	return c.next.Authorize(ctx, credentialID, req)
+}
@@ -0,0 +1,128 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/nodetl/nodetl/internal/domain"
+	"github.com/nodetl/nodetl/internal/node"
+)
+
+// errorPort is the output port failed nodes are routed through
+const errorPort = "error"
+
+var (
+	// errExecutionTimeout is the cancellation cause when a workflow exceeds its max duration
+	errExecutionTimeout = errors.New("execution timed out")
+	// errNodeTimeout is the cancellation cause when a node exceeds its timeout
+	errNodeTimeout = errors.New("node timed out")
+)
+
+// nodeFailure ties an execution error to the node that caused it
+type nodeFailure struct {
+	nodeID string
+	code   string
+	err    error
+}
+
+func (f *nodeFailure) Error() string {
+	return f.err.Error()
+}
+
+func (f *nodeFailure) Unwrap() error {
+	return f.err
+}
+
+// newNodeFailure wraps a node error, classifying timeouts. Errors that already
+// name a failed node, such as those from a loop body, are kept as they are.
+func newNodeFailure(nodeID string, err error) error {
+	var failure *nodeFailure
+	if errors.As(err, &failure) {
+		return err
+	}
+
+	code := domain.ErrorCodeNodeFailed
+	if errors.Is(err, errNodeTimeout) {
+		code = domain.ErrorCodeNodeTimeout
+	}
+	return &nodeFailure{nodeID: nodeID, code: code, err: err}
+}
+
+// toNodeError converts a node failure into the typed error handed to the
+// nodes connected to its error port
+func toNodeError(err error) *node.ExecutionError {
+	if errors.Is(err, errNodeTimeout) {
+		return &node.ExecutionError{
+			Type:       node.ErrorTypeTimeout,
+			Message:    err.Error(),
+			StatusCode: http.StatusGatewayTimeout,
+		}
+	}
+	// Copy so the node ID can be set without touching errors a node may reuse
+	execErr := *node.NewExecutionError(err)
+	return &execErr
+}
+
+// toExecutionError converts a run error into the error stored on the execution
+func toExecutionError(err error) *domain.ExecutionError {
+	execErr := &domain.ExecutionError{Message: err.Error()}
+
+	var failure *nodeFailure
+	if errors.As(err, &failure) {
+		execErr.NodeID = failure.nodeID
+		execErr.Code = failure.code
+	}
+	if errors.Is(err, errExecutionTimeout) {
+		execErr.Code = domain.ErrorCodeExecutionTimeout
+	}
+	if errors.Is(err, errExecutionCancelled) {
+		execErr.Code = domain.ErrorCodeExecutionCancelled
+	}
+	return execErr
+}
+
+// executeOnce runs a single attempt of a node, bounded by the node timeout.
+// The timeout is reported even if the node ignores its context. The returned
+// channel is closed once the attempt has returned, which may be after the
+// timeout, so a retry never runs alongside an attempt that is still going.
+func executeOnce(ctx context.Context, executor node.NodeExecutor, execCtx *node.ExecutionContext, nodeData domain.NodeData) (*node.ExecutionResult, <-chan struct{}, error) {
+	settled := make(chan struct{})
+	if nodeData.TimeoutMs <= 0 {
+		defer close(settled)
+		result, err := executor.Execute(ctx, execCtx, nodeData)
+		return result, settled, err
+	}
+
+	timeout := time.Duration(nodeData.TimeoutMs) * time.Millisecond
+	ctx, cancel := context.WithTimeoutCause(ctx, timeout, errNodeTimeout)
+
+	type outcome struct {
+		result *node.ExecutionResult
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		defer close(settled)
+		defer cancel()
+		result, err := executor.Execute(ctx, execCtx, nodeData)
+		done <- outcome{result, err}
+	}()
+
+	timeoutErr := fmt.Errorf("%w after %dms", errNodeTimeout, nodeData.TimeoutMs)
+	select {
+	case o := <-done:
+		failed := o.err != nil || (o.result != nil && o.result.Error != nil)
+		if failed && context.Cause(ctx) == errNodeTimeout {
+			return o.result, settled, timeoutErr
+		}
+		return o.result, settled, o.err
+	case <-ctx.Done():
+		if context.Cause(ctx) == errNodeTimeout {
+			return nil, settled, timeoutErr
+		}
+		return nil, settled, context.Cause(ctx)
+	}
+}
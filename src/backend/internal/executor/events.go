@@ -0,0 +1,257 @@
+package executor
+
+import (
+	"sync"
+	"time"
+
+	"github.com/nodetl/nodetl/internal/domain"
+	"github.com/nodetl/nodetl/internal/metrics"
+)
+
+const (
+	// eventHistoryLimit caps the events kept per execution for late subscribers
+	eventHistoryLimit = 1000
+	// eventRetention is how long the events of a finished execution are kept
+	eventRetention = 5 * time.Minute
+	// subscriberBuffer is the number of events a subscriber may fall behind by
+	// before it is dropped
+	subscriberBuffer = 256
+)
+
+// EventBus distributes execution events to in-process subscribers. It keeps
+// the events of each execution so subscribers that join late can catch up.
+type EventBus struct {
+	mu        sync.Mutex
+	streams   map[string]*eventStream
+	retention time.Duration
+	closed    bool
+}
+
+// eventStream holds the events and subscribers of one execution
+type eventStream struct {
+	seq         int
+	events      []domain.ExecutionEvent
+	subscribers map[chan domain.ExecutionEvent]struct{}
+	finished    bool
+}
+
+// NewEventBus creates a new event bus
+func NewEventBus() *EventBus {
+	return &EventBus{
+		streams:   make(map[string]*eventStream),
+		retention: eventRetention,
+	}
+}
+
+// Publish numbers the event and sends it to the execution's subscribers.
+// Subscribers that cannot keep up are disconnected rather than blocking the
+// run. A final event closes the stream.
+func (b *EventBus) Publish(event domain.ExecutionEvent) {
+	if b == nil {
+		return
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stream := b.stream(event.ExecutionID)
+	if stream.finished {
+		return
+	}
+	stream.seq++
+	event.Seq = stream.seq
+
+	stream.events = append(stream.events, event)
+	if len(stream.events) > eventHistoryLimit {
+		stream.events = stream.events[len(stream.events)-eventHistoryLimit:]
+	}
+
+	for ch := range stream.subscribers {
+		select {
+		case ch <- event:
+		default:
+			close(ch)
+			delete(stream.subscribers, ch)
+		}
+	}
+
+	if event.IsFinal() {
+		stream.finished = true
+		for ch := range stream.subscribers {
+			close(ch)
+		}
+		stream.subscribers = nil
+
+		executionID := event.ExecutionID
+		time.AfterFunc(b.retention, func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			if b.streams[executionID] == stream {
+				delete(b.streams, executionID)
+			}
+		})
+	}
+}
+
+// Subscribe returns the events of an execution published after the given
+// sequence number, and a channel delivering the ones still to come. The
+// channel is closed after the final event, or straight away if the execution
+// has already finished. unsubscribe must be called once the caller is done.
+func (b *EventBus) Subscribe(executionID string, after int) (past []domain.ExecutionEvent, events <-chan domain.ExecutionEvent, unsubscribe func()) {
+	ch := make(chan domain.ExecutionEvent, subscriberBuffer)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		close(ch)
+		return nil, ch, func() {}
+	}
+
+	stream := b.stream(executionID)
+	for _, event := range stream.events {
+		if event.Seq > after {
+			past = append(past, event)
+		}
+	}
+
+	if stream.finished {
+		close(ch)
+		return past, ch, func() {}
+	}
+
+	stream.subscribers[ch] = struct{}{}
+	return past, ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := stream.subscribers[ch]; ok {
+			delete(stream.subscribers, ch)
+			close(ch)
+		}
+		// Forget executions nobody published to, e.g. ones run elsewhere
+		if stream.seq == 0 && len(stream.subscribers) == 0 && b.streams[executionID] == stream {
+			delete(b.streams, executionID)
+		}
+	}
+}
+
+// Close disconnects all subscribers, e.g. on shutdown
+func (b *EventBus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for _, stream := range b.streams {
+		for ch := range stream.subscribers {
+			close(ch)
+		}
+		stream.subscribers = nil
+	}
+}
+
+// publishFinished publishes the final event of an execution and records it
+// in the execution metrics
+func (e *FlowExecutor) publishFinished(execution *domain.Execution) {
+	metrics.ObserveExecution(execution.WorkflowID.Hex(), execution.WorkflowName, string(execution.Status),
+		time.Duration(execution.Duration)*time.Millisecond)
+	e.events.Publish(domain.ExecutionEvent{
+		Type:        finalEventType(execution.Status),
+		ExecutionID: execution.ID.Hex(),
+		WorkflowID:  execution.WorkflowID.Hex(),
+		Status:      execution.Status,
+		Output:      execution.Output,
+		Error:       execution.Error,
+		Duration:    execution.Duration,
+	})
+}
+
+// RecordedEvents rebuilds the events of a finished execution from its stored
+// record, for executions whose events are no longer held by the bus
+func RecordedEvents(execution *domain.Execution) []domain.ExecutionEvent {
+	executionID := execution.ID.Hex()
+	workflowID := execution.WorkflowID.Hex()
+
+	events := []domain.ExecutionEvent{{
+		Type:        domain.EventExecutionStarted,
+		ExecutionID: executionID,
+		WorkflowID:  workflowID,
+		Status:      domain.ExecutionStatusRunning,
+		Timestamp:   execution.StartedAt,
+	}}
+	for _, nodeLog := range execution.NodeLogs {
+		events = append(events, domain.ExecutionEvent{
+			Type:        domain.EventNodeStarted,
+			ExecutionID: executionID,
+			WorkflowID:  workflowID,
+			NodeID:      nodeLog.NodeID,
+			NodeType:    nodeLog.NodeType,
+			NodeLabel:   nodeLog.NodeLabel,
+			Status:      domain.ExecutionStatusRunning,
+			Timestamp:   nodeLog.StartedAt,
+		})
+
+		event := domain.ExecutionEvent{
+			Type:        domain.EventNodeCompleted,
+			ExecutionID: executionID,
+			WorkflowID:  workflowID,
+			NodeID:      nodeLog.NodeID,
+			NodeType:    nodeLog.NodeType,
+			NodeLabel:   nodeLog.NodeLabel,
+			Status:      nodeLog.Status,
+			Output:      nodeLog.Output,
+			Duration:    nodeLog.Duration,
+		}
+		if nodeLog.CompletedAt != nil {
+			event.Timestamp = *nodeLog.CompletedAt
+		}
+		if nodeLog.Error != nil {
+			event.Type = domain.EventNodeFailed
+			event.Error = &domain.ExecutionError{NodeID: nodeLog.NodeID, Message: *nodeLog.Error}
+		}
+		events = append(events, event)
+	}
+
+	final := domain.ExecutionEvent{
+		Type:        finalEventType(execution.Status),
+		ExecutionID: executionID,
+		WorkflowID:  workflowID,
+		Status:      execution.Status,
+		Output:      execution.Output,
+		Error:       execution.Error,
+		Duration:    execution.Duration,
+	}
+	if execution.CompletedAt != nil {
+		final.Timestamp = *execution.CompletedAt
+	}
+	events = append(events, final)
+
+	for i := range events {
+		events[i].Seq = i + 1
+	}
+	return events
+}
+
+// finalEventType returns the event type ending an execution with the given status
+func finalEventType(status domain.ExecutionStatus) string {
+	switch status {
+	case domain.ExecutionStatusFailed:
+		return domain.EventExecutionFailed
+	case domain.ExecutionStatusCancelled:
+		return domain.EventExecutionCancelled
+	}
+	return domain.EventExecutionCompleted
+}
+
+// stream returns the stream of an execution, creating it if needed. The
+// caller must hold b.mu.
+func (b *EventBus) stream(executionID string) *eventStream {
+	stream, ok := b.streams[executionID]
+	if !ok {
+		stream = &eventStream{subscribers: make(map[chan domain.ExecutionEvent]struct{})}
+		b.streams[executionID] = stream
+	}
+	return stream
+}
@@ -0,0 +1,302 @@
+package executor
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nodetl/nodetl/internal/domain"
+	"github.com/nodetl/nodetl/internal/node"
+	"github.com/nodetl/nodetl/internal/repository"
+	"github.com/nodetl/nodetl/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func init() {
+	logger.Init("error", "console")
+}
+
+// newTestExecutor returns an executor backed by in-memory repositories
+// holding the given workflows
+func newTestExecutor(workflows ...*domain.Workflow) (*FlowExecutor, *memExecutions) {
+	workflowRepo := &memWorkflows{workflows: make(map[primitive.ObjectID]*domain.Workflow)}
+	for _, workflow := range workflows {
+		if workflow.ID.IsZero() {
+			workflow.ID = primitive.NewObjectID()
+		}
+		workflowRepo.workflows[workflow.ID] = workflow
+	}
+	executions := &memExecutions{docs: make(map[primitive.ObjectID][]byte)}
+
+	return &FlowExecutor{
+		workflowRepo:  workflowRepo,
+		executionRepo: executions,
+		nodeRegistry:  node.GetRegistry(),
+		running:       newRunRegistry(),
+		events:        NewEventBus(),
+	}, executions
+}
+
+type memWorkflows struct {
+	repository.WorkflowRepository
+	workflows map[primitive.ObjectID]*domain.Workflow
+}
+
+func (m *memWorkflows) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Workflow, error) {
+	workflow, ok := m.workflows[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *workflow
+	return &copied, nil
+}
+
+// memExecutions stores executions as BSON, so what is read back is what
+// Mongo would return. Every document written is kept in writes, and afterGet
+// runs once after the next GetByID to let tests interleave a change.
+type memExecutions struct {
+	repository.ExecutionRepository
+	mu       sync.Mutex
+	docs     map[primitive.ObjectID][]byte
+	writes   [][]byte
+	afterGet func()
+}
+
+func (m *memExecutions) Create(ctx context.Context, execution *domain.Execution) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	execution.ID = primitive.NewObjectID()
+	execution.StartedAt = time.Now()
+	return m.put(execution)
+}
+
+func (m *memExecutions) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Execution, error) {
+	m.mu.Lock()
+	execution, err := m.get(id)
+	afterGet := m.afterGet
+	m.afterGet = nil
+	m.mu.Unlock()
+
+	if afterGet != nil {
+		afterGet()
+	}
+	return execution, err
+}
+
+func (m *memExecutions) Update(ctx context.Context, execution *domain.Execution) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.put(execution)
+}
+
+func (m *memExecutions) ReplaceIfStatus(ctx context.Context, execution *domain.Execution, status domain.ExecutionStatus) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, err := m.get(execution.ID)
+	if err != nil || stored == nil || stored.Status != status {
+		return false, err
+	}
+	return true, m.put(execution)
+}
+
+func (m *memExecutions) AppendNodeLog(ctx context.Context, id primitive.ObjectID, nodeLog domain.NodeExecutionLog) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	execution, err := m.get(id)
+	if err != nil || execution == nil {
+		return err
+	}
+	execution.NodeLogs = append(execution.NodeLogs, nodeLog)
+	return m.put(execution)
+}
+
+func (m *memExecutions) Heartbeat(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	execution, err := m.get(id)
+	if err != nil || execution == nil || execution.Status != domain.ExecutionStatusRunning {
+		return false, err
+	}
+	now := time.Now()
+	execution.HeartbeatAt = &now
+	return execution.CancelRequested, m.put(execution)
+}
+
+func (m *memExecutions) RequestCancel(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	execution, err := m.get(id)
+	if err != nil || execution == nil || execution.Status != domain.ExecutionStatusRunning {
+		return false, err
+	}
+	execution.CancelRequested = true
+	return true, m.put(execution)
+}
+
+func (m *memExecutions) FindStale(ctx context.Context, staleBefore time.Time, limit int) ([]domain.Execution, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var executions []domain.Execution
+	for id := range m.docs {
+		execution, err := m.get(id)
+		if err != nil {
+			return nil, err
+		}
+		lastSeen := execution.StartedAt
+		if execution.HeartbeatAt != nil {
+			lastSeen = *execution.HeartbeatAt
+		}
+		if execution.Status == domain.ExecutionStatusRunning && lastSeen.Before(staleBefore) && len(executions) < limit {
+			executions = append(executions, *execution)
+		}
+	}
+	return executions, nil
+}
+
+func (m *memExecutions) put(execution *domain.Execution) error {
+	doc, err := bson.Marshal(execution)
+	if err != nil {
+		return err
+	}
+	m.docs[execution.ID] = doc
+	m.writes = append(m.writes, doc)
+	return nil
+}
+
+func (m *memExecutions) get(id primitive.ObjectID) (*domain.Execution, error) {
+	doc, ok := m.docs[id]
+	if !ok {
+		return nil, nil
+	}
+	var execution domain.Execution
+	if err := bson.Unmarshal(doc, &execution); err != nil {
+		return nil, err
+	}
+	return &execution, nil
+}
+
+// memJobs is an in-memory execution queue
+type memJobs struct {
+	mu   sync.Mutex
+	jobs []*domain.ExecutionJob
+}
+
+func (m *memJobs) Enqueue(ctx context.Context, job *domain.ExecutionJob) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job.ID = primitive.NewObjectID()
+	job.Status = domain.ExecutionJobStatusQueued
+	job.CreatedAt = time.Now()
+	m.jobs = append(m.jobs, job)
+	return nil
+}
+
+func (m *memJobs) ClaimNext(ctx context.Context, workerID string) (*domain.ExecutionJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, job := range m.jobs {
+		if job.Status == domain.ExecutionJobStatusQueued {
+			now := time.Now()
+			job.Status = domain.ExecutionJobStatusRunning
+			job.WorkerID = workerID
+			job.Attempts++
+			job.LockedAt = &now
+			copied := *job
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memJobs) Delete(ctx context.Context, id primitive.ObjectID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, job := range m.jobs {
+		if job.ID == id {
+			m.jobs = append(m.jobs[:i], m.jobs[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (m *memJobs) Requeue(ctx context.Context, job *domain.ExecutionJob) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, queued := range m.jobs {
+		if queued.ID == job.ID {
+			queued.Status = domain.ExecutionJobStatusQueued
+			queued.WorkerID = ""
+			queued.LockedAt = nil
+		}
+	}
+	return nil
+}
+
+func (m *memJobs) FindStaleClaims(ctx context.Context, lockedBefore time.Time, limit int) ([]domain.ExecutionJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var jobs []domain.ExecutionJob
+	for _, job := range m.jobs {
+		if job.Status == domain.ExecutionJobStatusRunning && job.LockedAt.Before(lockedBefore) && len(jobs) < limit {
+			jobs = append(jobs, *job)
+		}
+	}
+	return jobs, nil
+}
+
+func (m *memJobs) Release(ctx context.Context, job *domain.ExecutionJob) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, claimed := range m.jobs {
+		if claimed.ID == job.ID && claimed.Status == domain.ExecutionJobStatusRunning && claimed.LockedAt.Equal(*job.LockedAt) {
+			claimed.Status = domain.ExecutionJobStatusQueued
+			claimed.WorkerID = ""
+			claimed.LockedAt = nil
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *memJobs) DeleteByExecutionID(ctx context.Context, executionID primitive.ObjectID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, job := range m.jobs {
+		if job.ExecutionID == executionID {
+			m.jobs = append(m.jobs[:i], m.jobs[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// inputsWritten returns the execution and node inputs of every document
+// written
+func (m *memExecutions) inputsWritten() []map[string]any {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var inputs []map[string]any
+	for _, doc := range m.writes {
+		var execution domain.Execution
+		if err := bson.Unmarshal(doc, &execution); err != nil {
+			continue
+		}
+		inputs = append(inputs, execution.Input)
+		for _, nodeLog := range execution.NodeLogs {
+			inputs = append(inputs, nodeLog.Input)
+		}
+	}
+	return inputs
+}
+
+func mustObjectID(t *testing.T, hex string) primitive.ObjectID {
+	t.Helper()
+	id, err := primitive.ObjectIDFromHex(hex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
@@ -2,14 +2,20 @@ package executor
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/nodetl/nodetl/internal/domain"
+	"github.com/nodetl/nodetl/internal/metrics"
 	"github.com/nodetl/nodetl/internal/node"
+	"github.com/nodetl/nodetl/internal/redact"
 	"github.com/nodetl/nodetl/internal/repository"
+	"github.com/nodetl/nodetl/internal/tracing"
 	"github.com/nodetl/nodetl/pkg/logger"
+	"github.com/nodetl/nodetl/pkg/secrets"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -18,7 +24,14 @@ type FlowExecutor struct {
 	workflowRepo   repository.WorkflowRepository
 	executionRepo  repository.ExecutionRepository
 	nodeSchemaRepo repository.NodeSchemaRepository
+	projectRepo    *repository.ProjectRepository
+	credentials    node.CredentialResolver
+	retention      domain.RetentionPolicy // Server defaults for workflows and projects without a policy
+	redaction      *redact.Rules          // Server rules for hiding sensitive values in execution data
+	sealer         *secrets.Cipher        // Encrypts the values redaction hides, so reruns see them; nil to drop them
 	nodeRegistry   *node.Registry
+	running        *runRegistry
+	events         *EventBus
 }
 
 // NewFlowExecutor creates a new flow executor
@@ -26,22 +39,45 @@ func NewFlowExecutor(
 	workflowRepo repository.WorkflowRepository,
 	executionRepo repository.ExecutionRepository,
 	nodeSchemaRepo repository.NodeSchemaRepository,
+	projectRepo *repository.ProjectRepository,
+	credentials node.CredentialResolver,
+	retention domain.RetentionPolicy,
+	redaction *redact.Rules,
+	sealer *secrets.Cipher,
 ) *FlowExecutor {
 	return &FlowExecutor{
 		workflowRepo:   workflowRepo,
 		executionRepo:  executionRepo,
 		nodeSchemaRepo: nodeSchemaRepo,
+		projectRepo:    projectRepo,
+		credentials:    credentials,
+		retention:      retention,
+		redaction:      redaction,
+		sealer:         sealer,
 		nodeRegistry:   node.GetRegistry(),
+		running:        newRunRegistry(),
+		events:         NewEventBus(),
 	}
 }
 
+// Events returns the bus execution progress is published on
+func (e *FlowExecutor) Events() *EventBus {
+	return e.events
+}
+
 // ExecuteRequest contains the request to execute a workflow
 type ExecuteRequest struct {
-	WorkflowID  primitive.ObjectID
-	TriggerType string
-	TriggerPath string // Optional: specific trigger path for multi-trigger workflows
-	Input       map[string]any
-	Metadata    map[string]any
+	WorkflowID    primitive.ObjectID
+	TriggerType   string
+	TriggerPath   string // Optional: specific trigger path for multi-trigger workflows
+	TriggerNodeID string // Optional: specific trigger node, e.g. the schedule that fired
+	Environment   string // Optional: project environment to run in instead of the one the trigger is bound to
+	Input         map[string]any
+	Metadata      map[string]any
+
+	// parentEnvironment is the environment of the execution that started a
+	// child workflow, used when the child's project defines it
+	parentEnvironment string
 }
 
 // ExecuteResult contains the result of workflow execution
@@ -55,53 +91,84 @@ type ExecuteResult struct {
 
 // Execute runs a workflow
 func (e *FlowExecutor) Execute(ctx context.Context, req *ExecuteRequest) (*ExecuteResult, error) {
-	startTime := time.Now()
+	workflow, execution, err := e.createExecution(ctx, req, domain.ExecutionStatusRunning)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.runExecution(ctx, workflow, execution, req.TriggerPath, req.TriggerNodeID)
+}
 
+// createExecution loads the workflow and stores a new execution record with
+// the given initial status
+func (e *FlowExecutor) createExecution(ctx context.Context, req *ExecuteRequest, status domain.ExecutionStatus) (*domain.Workflow, *domain.Execution, error) {
 	// Get workflow
 	workflow, err := e.workflowRepo.GetByID(ctx, req.WorkflowID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get workflow: %w", err)
+		return nil, nil, fmt.Errorf("failed to get workflow: %w", err)
 	}
 	if workflow == nil {
-		return nil, fmt.Errorf("workflow not found")
+		return nil, nil, fmt.Errorf("workflow not found")
+	}
+
+	project, err := e.workflowProject(ctx, workflow)
+	if err != nil {
+		return nil, nil, err
+	}
+	environment, err := e.selectEnvironment(project, workflow, req)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	// Create execution record
 	execution := &domain.Execution{
 		WorkflowID:   workflow.ID,
 		WorkflowName: workflow.Name,
-		Status:       domain.ExecutionStatusRunning,
+		Status:       status,
 		TriggerType:  req.TriggerType,
+		Environment:  environment,
 		Input:        req.Input,
 		NodeLogs:     []domain.NodeExecutionLog{},
 		Metadata:     req.Metadata,
 	}
+	if maxAge := e.retentionPolicy(workflow, project).MaxAge(); maxAge > 0 {
+		expiresAt := time.Now().Add(maxAge)
+		execution.ExpiresAt = &expiresAt
+	}
 
-	// Generate TraceID for this execution
-	traceID := uuid.New().String()
+	// Executions continue the trace of their caller, if any, so the trace ID
+	// in error responses can be looked up in the tracing backend
+	traceID, traceparent := tracing.NewTrace(ctx)
 	if execution.Metadata == nil {
 		execution.Metadata = make(map[string]any)
 	}
-	execution.Metadata["traceId"] = traceID
+	execution.Metadata[metaTraceID] = traceID
+	if traceparent != "" {
+		execution.Metadata[metaTraceparent] = traceparent
+	}
 
-	if err := e.executionRepo.Create(ctx, execution); err != nil {
-		return nil, fmt.Errorf("failed to create execution: %w", err)
+	if err := e.storeNewExecution(ctx, execution, e.redactionRules(ctx, workflow)); err != nil {
+		return nil, nil, fmt.Errorf("failed to create execution: %w", err)
 	}
 
+	return workflow, execution, nil
+}
+
+// runExecution executes a stored execution record and persists its outcome
+func (e *FlowExecutor) runExecution(ctx context.Context, workflow *domain.Workflow, execution *domain.Execution, triggerPath string, triggerNodeID string) (*ExecuteResult, error) {
 	logger.Log.Infow("Starting workflow execution",
 		"workflowId", workflow.ID.Hex(),
 		"executionId", execution.ID.Hex(),
-		"triggerType", req.TriggerType,
+		"triggerType", execution.TriggerType,
 	)
 
-	// Build node graph
-	graph := e.buildNodeGraph(workflow)
-
 	// Find trigger node (entry point)
-	// If TriggerPath is specified, find the matching trigger node
+	// If a trigger path is specified, find the matching trigger node
 	var triggerNode *domain.Node
-	if req.TriggerPath != "" {
-		triggerNode = e.findTriggerNodeByPath(workflow.Nodes, req.TriggerPath)
+	if triggerNodeID != "" {
+		triggerNode = e.findTriggerNodeByID(workflow.Nodes, triggerNodeID)
+	} else if triggerPath != "" {
+		triggerNode = e.findTriggerNodeByPath(workflow.Nodes, triggerPath)
 	} else {
 		triggerNode = e.findTriggerNode(workflow.Nodes)
 	}
@@ -109,12 +176,73 @@ func (e *FlowExecutor) Execute(ctx context.Context, req *ExecuteRequest) (*Execu
 		err := fmt.Errorf("no trigger node found in workflow")
 		execution.Status = domain.ExecutionStatusFailed
 		execution.Error = &domain.ExecutionError{Message: err.Error()}
-		e.executionRepo.Update(ctx, execution)
+		e.publishFinished(e.saveExecution(ctx, execution, e.redactionRules(ctx, workflow)))
+		return nil, err
+	}
+
+	return e.runFrom(ctx, workflow, execution, triggerNode, execution.Input, nil)
+}
+
+// runFrom runs an execution starting at the given node with the given input
+// and persists its outcome
+func (e *FlowExecutor) runFrom(ctx context.Context, workflow *domain.Workflow, execution *domain.Execution, entry *domain.Node, input map[string]any, outputs map[string]any) (*ExecuteResult, error) {
+	startTime := time.Now()
+	ctx, span := startExecutionSpan(ctx, workflow, execution)
+	defer endExecutionSpan(span, execution)
+	redaction := e.redactionRules(ctx, workflow)
+
+	project, err := e.workflowProject(ctx, workflow)
+	var environment *domain.Environment
+	if err == nil {
+		environment, err = loadEnvironment(project, execution.Environment)
+	}
+	if err != nil {
+		now := time.Now()
+		execution.Status = domain.ExecutionStatusFailed
+		execution.Error = &domain.ExecutionError{Message: err.Error()}
+		execution.CompletedAt = &now
+		e.publishFinished(e.saveExecution(ctx, execution, redaction))
 		return nil, err
 	}
 
-	// Execute the workflow starting from trigger node
-	output, execErr := e.executeNode(ctx, workflow, execution, triggerNode, req.Input, nil, req.Input, graph)
+	// Register the run so it can be cancelled, and bound it by the workflow's
+	// maximum duration, if any. The outcome is still persisted with the
+	// caller's context.
+	runCtx, cancelRun := context.WithCancelCause(ctx)
+	defer cancelRun(nil)
+	e.running.add(execution.ID, cancelRun)
+	defer e.running.remove(execution.ID)
+	defer metrics.ExecutionStarted(workflow.ID.Hex(), workflow.Name)()
+	defer e.startHeartbeat(execution.ID, cancelRun)()
+
+	var maxDuration int
+	if workflow.Settings != nil {
+		maxDuration = workflow.Settings.MaxDurationMs
+	}
+	if maxDuration > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeoutCause(runCtx, time.Duration(maxDuration)*time.Millisecond, errExecutionTimeout)
+		defer cancel()
+	}
+
+	e.events.Publish(domain.ExecutionEvent{
+		Type:        domain.EventExecutionStarted,
+		ExecutionID: execution.ID.Hex(),
+		WorkflowID:  workflow.ID.Hex(),
+		NodeID:      entry.ID,
+		Status:      domain.ExecutionStatusRunning,
+	})
+
+	// Execute the workflow starting from the entry node
+	graph := e.buildNodeGraph(workflow)
+	run := newExecutionRun(e, workflow, execution, environment, redaction, graph, execution.Input, outputs)
+	output, execErr := run.run(runCtx, entry, input)
+	if execErr != nil && context.Cause(runCtx) == errExecutionTimeout {
+		execErr = fmt.Errorf("%w: exceeded the maximum duration of %dms", errExecutionTimeout, maxDuration)
+	}
+	if execErr != nil && context.Cause(runCtx) == errExecutionCancelled {
+		execErr = errExecutionCancelled
+	}
 
 	// Update execution record
 	duration := time.Since(startTime).Milliseconds()
@@ -122,17 +250,21 @@ func (e *FlowExecutor) Execute(ctx context.Context, req *ExecuteRequest) (*Execu
 	now := time.Now()
 	execution.CompletedAt = &now
 
-	if execErr != nil {
+	if errors.Is(execErr, errExecutionCancelled) {
+		execution.Status = domain.ExecutionStatusCancelled
+		execution.Error = toExecutionError(execErr)
+	} else if execErr != nil {
 		execution.Status = domain.ExecutionStatusFailed
-		execution.Error = &domain.ExecutionError{
-			Message: execErr.Error(),
-		}
+		execution.Error = toExecutionError(execErr)
 	} else {
 		execution.Status = domain.ExecutionStatusCompleted
 		execution.Output = output
+		if !e.retentionPolicy(workflow, project).KeepsSuccessfulPayloads() {
+			purgePayloads(execution)
+		}
 	}
 
-	e.executionRepo.Update(ctx, execution)
+	e.publishFinished(e.saveExecution(ctx, execution, redaction))
 
 	logger.Log.Infow("Workflow execution completed",
 		"workflowId", workflow.ID.Hex(),
@@ -150,6 +282,53 @@ func (e *FlowExecutor) Execute(ctx context.Context, req *ExecuteRequest) (*Execu
 	}, nil
 }
 
+// RunPending executes an execution that was queued with status pending
+func (e *FlowExecutor) RunPending(ctx context.Context, executionID primitive.ObjectID, triggerPath string) (*ExecuteResult, error) {
+	execution, err := e.executionRepo.GetByID(ctx, executionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get execution: %w", err)
+	}
+	if execution == nil {
+		return nil, fmt.Errorf("execution not found")
+	}
+	if execution.Status != domain.ExecutionStatusPending {
+		return nil, fmt.Errorf("execution %s is not pending (status: %s)", executionID.Hex(), execution.Status)
+	}
+
+	workflow, err := e.workflowRepo.GetByID(ctx, execution.WorkflowID)
+	if err != nil || workflow == nil {
+		if err == nil {
+			err = fmt.Errorf("workflow not found")
+		}
+		now := time.Now()
+		execution.Status = domain.ExecutionStatusFailed
+		execution.Error = &domain.ExecutionError{Message: err.Error()}
+		execution.CompletedAt = &now
+		if stored, _ := e.executionRepo.ReplaceIfStatus(ctx, execution, domain.ExecutionStatusPending); stored {
+			e.publishFinished(execution)
+		}
+		return nil, err
+	}
+
+	// Only start if the execution was not cancelled since it was read
+	execution.Status = domain.ExecutionStatusRunning
+	execution.StartedAt = time.Now()
+	stored, err := e.executionRepo.ReplaceIfStatus(ctx, execution, domain.ExecutionStatusPending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update execution: %w", err)
+	}
+	if !stored {
+		return nil, fmt.Errorf("execution %s is no longer pending", executionID.Hex())
+	}
+
+	// Restored only now, as the updates above store the execution as read
+	e.unseal(execution)
+
+	// Scheduled runs re-queued after a crash start from the trigger that fired
+	triggerNodeID, _ := execution.Metadata["triggerNodeId"].(string)
+	return e.runExecution(ctx, workflow, execution, triggerPath, triggerNodeID)
+}
+
 // NodeGraph represents the workflow as a graph
 type NodeGraph struct {
 	Nodes   map[string]*domain.Node
@@ -177,6 +356,102 @@ func (e *FlowExecutor) buildNodeGraph(workflow *domain.Workflow) *NodeGraph {
 	return graph
 }
 
+// ReachableFrom returns the IDs of all nodes reachable from the given node,
+// including the node itself
+func (g *NodeGraph) ReachableFrom(nodeID string) map[string]bool {
+	return g.reachableFrom([]string{nodeID}, "")
+}
+
+// reachableFrom walks the graph from several start nodes without passing
+// through the stop node
+func (g *NodeGraph) reachableFrom(starts []string, stop string) map[string]bool {
+	reachable := make(map[string]bool)
+	queue := []string{}
+	for _, id := range starts {
+		if id != stop && !reachable[id] {
+			reachable[id] = true
+			queue = append(queue, id)
+		}
+	}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, edge := range g.Edges[current] {
+			if _, ok := g.Nodes[edge.Target]; !ok || reachable[edge.Target] || edge.Target == stop {
+				continue
+			}
+			reachable[edge.Target] = true
+			queue = append(queue, edge.Target)
+		}
+	}
+	return reachable
+}
+
+// inEdgeIndex returns the position of an edge among the incoming edges of
+// its target
+func (g *NodeGraph) inEdgeIndex(edge domain.Edge) int {
+	for i, in := range g.InEdges[edge.Target] {
+		if in == edge {
+			return i
+		}
+	}
+	return len(g.InEdges[edge.Target])
+}
+
+// BackEdges returns the edges that close a cycle when the graph is walked
+// depth-first from the given node
+func (g *NodeGraph) BackEdges(nodeID string) map[domain.Edge]bool {
+	backEdges := make(map[domain.Edge]bool)
+	onStack := make(map[string]bool)
+	visited := make(map[string]bool)
+
+	var visit func(id string)
+	visit = func(id string) {
+		visited[id] = true
+		onStack[id] = true
+		for _, edge := range g.Edges[id] {
+			if _, ok := g.Nodes[edge.Target]; !ok {
+				continue
+			}
+			if onStack[edge.Target] {
+				backEdges[edge] = true
+				continue
+			}
+			if !visited[edge.Target] {
+				visit(edge.Target)
+			}
+		}
+		onStack[id] = false
+	}
+	visit(nodeID)
+
+	return backEdges
+}
+
+// entryBackEdges returns the back edges of a run that enters the graph at
+// the given node. They are found from a trigger that reaches the node, so a
+// replay from inside a loop body stops at the edge back to the loop node
+// instead of running the loop again.
+func (g *NodeGraph) entryBackEdges(entryID string) map[domain.Edge]bool {
+	if entry, ok := g.Nodes[entryID]; ok && entry.Type == domain.NodeTypeTrigger {
+		return g.BackEdges(entryID)
+	}
+
+	var triggers []string
+	for id, n := range g.Nodes {
+		if n.Type == domain.NodeTypeTrigger {
+			triggers = append(triggers, id)
+		}
+	}
+	sort.Strings(triggers)
+	for _, id := range triggers {
+		if g.ReachableFrom(id)[entryID] {
+			return g.BackEdges(id)
+		}
+	}
+	return g.BackEdges(entryID)
+}
+
 func (e *FlowExecutor) findTriggerNode(nodes []domain.Node) *domain.Node {
 	for i := range nodes {
 		if nodes[i].Type == domain.NodeTypeTrigger {
@@ -186,6 +461,16 @@ func (e *FlowExecutor) findTriggerNode(nodes []domain.Node) *domain.Node {
 	return nil
 }
 
+// findTriggerNodeByID finds the trigger node with the given ID
+func (e *FlowExecutor) findTriggerNodeByID(nodes []domain.Node, id string) *domain.Node {
+	for i := range nodes {
+		if nodes[i].Type == domain.NodeTypeTrigger && nodes[i].ID == id {
+			return &nodes[i]
+		}
+	}
+	return nil
+}
+
 // findTriggerNodeByPath finds a trigger node with matching webhookPath
 func (e *FlowExecutor) findTriggerNodeByPath(nodes []domain.Node, path string) *domain.Node {
 	for i := range nodes {
@@ -199,33 +484,9 @@ func (e *FlowExecutor) findTriggerNodeByPath(nodes []domain.Node, path string) *
 	return e.findTriggerNode(nodes)
 }
 
-func (e *FlowExecutor) executeNode(
-	ctx context.Context,
-	workflow *domain.Workflow,
-	execution *domain.Execution,
-	currentNode *domain.Node,
-	input map[string]any,
-	previousInput map[string]any,
-	triggerInput map[string]any,
-	graph *NodeGraph,
-) (map[string]any, error) {
-	startTime := time.Now()
-
-	// Get TraceID from execution metadata
-	traceID := ""
-	if execution.Metadata != nil {
-		if tid, ok := execution.Metadata["traceId"].(string); ok {
-			traceID = tid
-		}
-	}
-
-	// Get executor for this node type
-	executor, ok := e.nodeRegistry.Get(currentNode.Type)
-	if !ok {
-		return nil, fmt.Errorf("no executor found for node type: %s", currentNode.Type)
-	}
-
-	// Prepare node data - for Transform nodes, load mappings from NodeSchema
+// prepareNodeData returns the configuration used to run a node.
+// For Transform nodes, mappings are loaded from the NodeSchema.
+func (e *FlowExecutor) prepareNodeData(ctx context.Context, workflow *domain.Workflow, currentNode *domain.Node) domain.NodeData {
 	nodeData := currentNode.Data
 	if currentNode.Type == domain.NodeTypeTransform && e.nodeSchemaRepo != nil {
 		schema, err := e.nodeSchemaRepo.GetByNode(ctx, workflow.ID.Hex(), currentNode.ID)
@@ -242,95 +503,30 @@ func (e *FlowExecutor) executeNode(
 			nodeData.MappingRules = mappingRules
 		}
 	}
+	return nodeData
+}
 
-	// Create execution context
-	execCtx := &node.ExecutionContext{
-		WorkflowID:    workflow.ID.Hex(),
-		ExecutionID:   execution.ID.Hex(),
-		NodeID:        currentNode.ID,
-		TraceID:       traceID,
-		Input:         input,
-		PreviousInput: previousInput,
-		TriggerInput:  triggerInput,
-		Variables:     workflow.Variables,
-	}
-
-	// Execute node
-	result, err := executor.Execute(ctx, execCtx, nodeData)
-
-	// Record node execution log
-	nodeLog := domain.NodeExecutionLog{
-		NodeID:    currentNode.ID,
-		NodeType:  currentNode.Type,
-		NodeLabel: currentNode.Label,
-		Input:     input,
-		StartedAt: startTime,
-	}
-
-	now := time.Now()
-	nodeLog.CompletedAt = &now
-	nodeLog.Duration = time.Since(startTime).Milliseconds()
-
-	if err != nil || (result != nil && result.Error != nil) {
-		nodeLog.Status = domain.ExecutionStatusFailed
-		errMsg := ""
-		if err != nil {
-			errMsg = err.Error()
-		} else if result.Error != nil {
-			errMsg = result.Error.Error()
-		}
-		nodeLog.Error = &errMsg
-	} else {
-		nodeLog.Status = domain.ExecutionStatusCompleted
-		nodeLog.Output = result.Output
-	}
-
-	if result != nil {
-		nodeLog.Logs = result.Logs
-	}
-
-	execution.NodeLogs = append(execution.NodeLogs, nodeLog)
-
+// ExecuteByEndpoint executes a workflow by its endpoint path
+func (e *FlowExecutor) ExecuteByEndpoint(ctx context.Context, path string, input map[string]any) (*ExecuteResult, error) {
+	req, _, err := e.EndpointRequest(ctx, path, input)
 	if err != nil {
 		return nil, err
 	}
-	if result.Error != nil {
-		return nil, result.Error
-	}
-
-	// Find next node(s) based on output port
-	nextEdges := graph.Edges[currentNode.ID]
-	for _, edge := range nextEdges {
-		// Check if this edge matches the output port
-		if edge.SourceHandle != "" && result.NextPort != "" && edge.SourceHandle != result.NextPort {
-			continue
-		}
-
-		nextNode, ok := graph.Nodes[edge.Target]
-		if !ok {
-			continue
-		}
-
-		// Execute next node with current output as input
-		// Pass current input as previousInput for next node
-		return e.executeNode(ctx, workflow, execution, nextNode, result.Output, execCtx.Input, execCtx.TriggerInput, graph)
-	}
-
-	// No more nodes to execute, return final output
-	return result.Output, nil
+	return e.Execute(ctx, req)
 }
 
-// ExecuteByEndpoint executes a workflow by its endpoint path
-func (e *FlowExecutor) ExecuteByEndpoint(ctx context.Context, path string, input map[string]any) (*ExecuteResult, error) {
+// EndpointRequest resolves the active workflow serving an endpoint path and
+// builds the request to execute it
+func (e *FlowExecutor) EndpointRequest(ctx context.Context, path string, input map[string]any) (*ExecuteRequest, *domain.Workflow, error) {
 	workflow, err := e.workflowRepo.GetByEndpointPath(ctx, path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get workflow by endpoint: %w", err)
+		return nil, nil, fmt.Errorf("failed to get workflow by endpoint: %w", err)
 	}
 	if workflow == nil {
-		return nil, fmt.Errorf("no active workflow found for endpoint: %s", path)
+		return nil, nil, fmt.Errorf("no active workflow found for endpoint: %s", path)
 	}
 
-	return e.Execute(ctx, &ExecuteRequest{
+	return &ExecuteRequest{
 		WorkflowID:  workflow.ID,
 		TriggerType: "webhook",
 		TriggerPath: path, // Pass trigger path to find specific trigger node
@@ -339,5 +535,5 @@ func (e *FlowExecutor) ExecuteByEndpoint(ctx context.Context, path string, input
 			"endpoint":  path,
 			"requestId": uuid.New().String(),
 		},
-	})
+	}, workflow, nil
 }
@@ -0,0 +1,291 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nodetl/nodetl/internal/domain"
+	"github.com/nodetl/nodetl/internal/repository"
+	"github.com/nodetl/nodetl/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// heartbeatInterval is how often a running execution records that it is
+// alive and checks whether it was cancelled elsewhere
+var heartbeatInterval = 15 * time.Second
+
+// reaperBatchSize limits the stale executions recovered per pass
+const reaperBatchSize = 100
+
+// startHeartbeat refreshes the heartbeat of a running execution until the
+// returned function is called. The run is cancelled once a heartbeat finds
+// that another instance requested it.
+func (e *FlowExecutor) startHeartbeat(executionID primitive.ObjectID, cancelRun context.CancelCauseFunc) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+
+		for {
+			cancelRequested, err := e.executionRepo.Heartbeat(ctx, executionID)
+			if err != nil && ctx.Err() == nil {
+				logger.Log.Warnw("Failed to record execution heartbeat", "executionId", executionID.Hex(), "error", err)
+			}
+			if cancelRequested {
+				cancelRun(errExecutionCancelled)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// Reaper recovers executions left running by an instance that crashed or was
+// killed. Executions without a heartbeat for longer than the stale threshold
+// are re-queued when their workflow is idempotent, and failed otherwise.
+type Reaper struct {
+	executor      *FlowExecutor
+	jobRepo       repository.ExecutionJobRepository
+	staleAfter    time.Duration
+	interval      time.Duration
+	maxRecoveries int
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewReaper creates a new reaper
+func NewReaper(executor *FlowExecutor, jobRepo repository.ExecutionJobRepository, staleAfter, interval time.Duration, maxRecoveries int) *Reaper {
+	// Leave room for a few missed heartbeats before giving up on a run
+	if staleAfter < 2*heartbeatInterval {
+		staleAfter = 2 * heartbeatInterval
+	}
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	if maxRecoveries < 0 {
+		maxRecoveries = 0
+	}
+
+	return &Reaper{
+		executor:      executor,
+		jobRepo:       jobRepo,
+		staleAfter:    staleAfter,
+		interval:      interval,
+		maxRecoveries: maxRecoveries,
+	}
+}
+
+// Start recovers executions abandoned before startup and keeps checking
+// for new ones in the background
+func (r *Reaper) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	r.wg.Add(1)
+	go r.loop(ctx)
+
+	logger.Log.Infow("Execution reaper started",
+		"staleAfter", r.staleAfter.String(),
+		"interval", r.interval.String(),
+	)
+}
+
+// Stop stops the reaper and waits for the current pass to finish
+func (r *Reaper) Stop() {
+	if r.cancel == nil {
+		return
+	}
+	r.cancel()
+	r.wg.Wait()
+	logger.Log.Info("Execution reaper stopped")
+}
+
+func (r *Reaper) loop(ctx context.Context) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.Reap(ctx); err != nil && ctx.Err() == nil {
+			logger.Log.Errorw("Failed to recover stale executions", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Reap recovers every execution that is currently stale, and the jobs of
+// workers that died before they started their execution
+func (r *Reaper) Reap(ctx context.Context) error {
+	staleBefore := time.Now().Add(-r.staleAfter)
+
+	for {
+		executions, err := r.executor.executionRepo.FindStale(ctx, staleBefore, reaperBatchSize)
+		if err != nil {
+			return err
+		}
+		for i := range executions {
+			if err := r.recoverExecution(ctx, &executions[i], staleBefore); err != nil {
+				return err
+			}
+		}
+		if len(executions) < reaperBatchSize {
+			break
+		}
+	}
+
+	jobs, err := r.jobRepo.FindStaleClaims(ctx, staleBefore, reaperBatchSize)
+	if err != nil {
+		return err
+	}
+	for i := range jobs {
+		if err := r.recoverJob(ctx, &jobs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recoverJob handles a job claimed longer ago than the stale threshold. A
+// worker marks the execution running right after claiming it, so a job whose
+// execution is still pending lost its worker and is queued again. Jobs of
+// finished executions are left over and deleted; running ones are recovered
+// through their heartbeat.
+func (r *Reaper) recoverJob(ctx context.Context, job *domain.ExecutionJob) error {
+	execution, err := r.executor.executionRepo.GetByID(ctx, job.ExecutionID)
+	if err != nil {
+		return fmt.Errorf("failed to get execution: %w", err)
+	}
+
+	switch {
+	case execution != nil && execution.Status == domain.ExecutionStatusRunning:
+		return nil
+	case execution != nil && execution.Status == domain.ExecutionStatusPending:
+		released, err := r.jobRepo.Release(ctx, job)
+		if err != nil {
+			return fmt.Errorf("failed to release job of execution %s: %w", job.ExecutionID.Hex(), err)
+		}
+		if released {
+			logger.Log.Warnw("Re-queued job of a worker that died",
+				"executionId", job.ExecutionID.Hex(),
+				"workerId", job.WorkerID,
+			)
+		}
+		return nil
+	default:
+		return r.jobRepo.Delete(ctx, job.ID)
+	}
+}
+
+// recoverExecution re-queues or fails a single stale execution. Another
+// instance may get to it first, in which case it is left alone.
+func (r *Reaper) recoverExecution(ctx context.Context, execution *domain.Execution, staleBefore time.Time) error {
+	lastSeen := execution.StartedAt
+	if execution.HeartbeatAt != nil {
+		lastSeen = *execution.HeartbeatAt
+	}
+
+	workflow, err := r.executor.workflowRepo.GetByID(ctx, execution.WorkflowID)
+	if err != nil {
+		return fmt.Errorf("failed to get workflow: %w", err)
+	}
+	idempotent := workflow != nil && workflow.Settings != nil && workflow.Settings.Idempotent
+
+	switch {
+	case execution.CancelRequested:
+		// Its runner died before it saw the cancellation
+		execution.Status = domain.ExecutionStatusCancelled
+		execution.Error = &domain.ExecutionError{
+			Message: errExecutionCancelled.Error(),
+			Code:    domain.ErrorCodeExecutionCancelled,
+		}
+	case idempotent && execution.Recoveries < r.maxRecoveries:
+		return r.requeue(ctx, execution, staleBefore, lastSeen)
+	default:
+		message := fmt.Sprintf("execution abandoned: no heartbeat since %s", lastSeen.UTC().Format(time.RFC3339))
+		if idempotent {
+			message += fmt.Sprintf(" after %d recoveries", execution.Recoveries)
+		}
+		execution.Status = domain.ExecutionStatusFailed
+		execution.Error = &domain.ExecutionError{
+			Message: message,
+			Code:    domain.ErrorCodeExecutionAbandoned,
+		}
+	}
+
+	now := time.Now()
+	execution.CompletedAt = &now
+	execution.Duration = lastSeen.Sub(execution.StartedAt).Milliseconds()
+
+	stored, err := r.executor.executionRepo.ReplaceIfStale(ctx, execution, staleBefore)
+	if err != nil || !stored {
+		return err
+	}
+	if err := r.jobRepo.DeleteByExecutionID(ctx, execution.ID); err != nil {
+		logger.Log.Warnw("Failed to delete job of abandoned execution", "executionId", execution.ID.Hex(), "error", err)
+	}
+	r.executor.publishFinished(execution)
+
+	logger.Log.Warnw("Finished abandoned execution",
+		"executionId", execution.ID.Hex(),
+		"status", execution.Status,
+		"workflowId", execution.WorkflowID.Hex(),
+		"lastSeen", lastSeen,
+	)
+	return nil
+}
+
+// requeue resets a stale execution to pending and queues it to run again from
+// its trigger with the original input
+func (r *Reaper) requeue(ctx context.Context, execution *domain.Execution, staleBefore time.Time, lastSeen time.Time) error {
+	execution.Status = domain.ExecutionStatusPending
+	execution.Output = nil
+	execution.Error = nil
+	execution.NodeLogs = []domain.NodeExecutionLog{}
+	execution.CompletedAt = nil
+	execution.Duration = 0
+	execution.HeartbeatAt = nil
+	execution.Recoveries++
+
+	stored, err := r.executor.executionRepo.ReplaceIfStale(ctx, execution, staleBefore)
+	if err != nil || !stored {
+		return err
+	}
+
+	job := &domain.ExecutionJob{
+		ExecutionID: execution.ID,
+		WorkflowID:  execution.WorkflowID,
+	}
+	if path, ok := execution.Metadata["endpoint"].(string); ok {
+		job.TriggerPath = path
+	}
+	if err := r.jobRepo.Requeue(ctx, job); err != nil {
+		return fmt.Errorf("failed to requeue execution %s: %w", execution.ID.Hex(), err)
+	}
+
+	logger.Log.Warnw("Re-queued abandoned execution",
+		"executionId", execution.ID.Hex(),
+		"workflowId", execution.WorkflowID.Hex(),
+		"lastSeen", lastSeen,
+		"recoveries", execution.Recoveries,
+	)
+	return nil
+}
@@ -0,0 +1,43 @@
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nodetl/nodetl/internal/domain"
+)
+
+func TestReapRequeuesJobOfWorkerThatDiedBeforeStarting(t *testing.T) {
+	ctx := context.Background()
+	workflow := authorizationWorkflow()
+	e, executions := newTestExecutor(workflow)
+
+	jobs := &memJobs{}
+	pool := NewWorkerPool(e, jobs, 1, 0)
+	queued, err := pool.Submit(ctx, &ExecuteRequest{WorkflowID: workflow.ID, Input: authorizedRequest()})
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+
+	// A worker claims the job and dies before it starts the execution
+	job, _ := jobs.ClaimNext(ctx, "dead-worker")
+	lockedAt := time.Now().Add(-time.Hour)
+	jobs.jobs[0].LockedAt = &lockedAt
+
+	reaper := NewReaper(e, jobs, time.Minute, time.Minute, 0)
+	if err := reaper.Reap(ctx); err != nil {
+		t.Fatalf("Reap() error = %v", err)
+	}
+	if jobs.jobs[0].Status != domain.ExecutionJobStatusQueued {
+		t.Fatalf("job %s status = %s, want it queued again", job.ID.Hex(), jobs.jobs[0].Status)
+	}
+
+	if !pool.runNext(ctx, "worker-1") {
+		t.Fatal("runNext() found no job")
+	}
+	execution, _ := executions.GetByID(ctx, mustObjectID(t, queued.ExecutionID))
+	if execution.Status != domain.ExecutionStatusCompleted {
+		t.Fatalf("status = %s, want completed", execution.Status)
+	}
+}
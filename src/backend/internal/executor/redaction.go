@@ -0,0 +1,186 @@
+package executor
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/nodetl/nodetl/internal/domain"
+	"github.com/nodetl/nodetl/internal/redact"
+	"github.com/nodetl/nodetl/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// redactionRules returns the rules hiding sensitive values in the executions
+// of a workflow: the server rules, the workflow's redactPaths and the fields
+// marked sensitive in the schemas of its transform nodes
+func (e *FlowExecutor) redactionRules(ctx context.Context, workflow *domain.Workflow) *redact.Rules {
+	var paths []string
+	if workflow.Settings != nil {
+		paths = append(paths, workflow.Settings.RedactPaths...)
+	}
+	if e.nodeSchemaRepo != nil {
+		schemas, err := e.nodeSchemaRepo.ListByWorkflow(ctx, workflow.ID.Hex())
+		if err != nil {
+			logger.Log.Warnw("Failed to load node schemas for redaction", "workflowId", workflow.ID.Hex(), "error", err)
+		}
+		for _, schema := range schemas {
+			if schema.SourceSchema != nil {
+				paths = append(paths, sensitivePaths(schema.SourceSchema.Fields)...)
+			}
+			if schema.TargetSchema != nil {
+				paths = append(paths, sensitivePaths(schema.TargetSchema.Fields)...)
+			}
+		}
+	}
+	if len(paths) == 0 {
+		return e.redaction
+	}
+
+	rules, err := e.redaction.WithPaths(paths...)
+	if err != nil {
+		logger.Log.Warnw("Ignoring invalid redaction paths", "workflowId", workflow.ID.Hex(), "error", err)
+	}
+	return rules
+}
+
+// sensitivePaths returns the paths of the schema fields marked sensitive,
+// including nested ones
+func sensitivePaths(fields []domain.SchemaField) []string {
+	var paths []string
+	for _, field := range fields {
+		if field.Sensitive {
+			path := field.Path
+			if path == "" {
+				path = field.Name
+			}
+			paths = append(paths, path)
+		}
+		paths = append(paths, sensitivePaths(field.Children)...)
+	}
+	return paths
+}
+
+// storeNewExecution stores a redacted copy of a new execution
+func (e *FlowExecutor) storeNewExecution(ctx context.Context, execution *domain.Execution, rules *redact.Rules) error {
+	stored := e.storedExecution(execution, rules)
+	if err := e.executionRepo.Create(ctx, stored); err != nil {
+		return err
+	}
+	execution.ID = stored.ID
+	execution.StartedAt = stored.StartedAt
+	return nil
+}
+
+// saveExecution stores a redacted copy of an execution and returns the copy,
+// which is what may be published
+func (e *FlowExecutor) saveExecution(ctx context.Context, execution *domain.Execution, rules *redact.Rules) *domain.Execution {
+	stored := e.storedExecution(execution, rules)
+	if err := e.executionRepo.Update(ctx, stored); err != nil {
+		logger.Log.Warnw("Failed to update execution", "executionId", execution.ID.Hex(), "error", err)
+	}
+	return stored
+}
+
+// storedExecution returns the redacted copy of an execution that is stored.
+// The input and node data redaction changed are also kept encrypted, never
+// returned by the API, so queued runs, recovered runs and replays see what
+// the execution actually received rather than the placeholders.
+func (e *FlowExecutor) storedExecution(execution *domain.Execution, rules *redact.Rules) *domain.Execution {
+	stored := rules.Execution(execution)
+	if stored == execution {
+		return execution
+	}
+	stored.SealedInput = e.seal(execution.Input, stored.Input)
+	for i := range stored.NodeLogs {
+		stored.NodeLogs[i] = e.storedNodeLog(execution.NodeLogs[i], rules)
+	}
+	return stored
+}
+
+// storedNodeLog returns the redacted copy of a node log that is stored, with
+// the input and output redaction changed kept encrypted
+func (e *FlowExecutor) storedNodeLog(nodeLog domain.NodeExecutionLog, rules *redact.Rules) domain.NodeExecutionLog {
+	stored := rules.NodeLog(nodeLog)
+	stored.SealedInput = e.seal(nodeLog.Input, stored.Input)
+	stored.SealedOutput = e.seal(nodeLog.Output, stored.Output)
+	return stored
+}
+
+// seal encrypts data if redaction changed it. Without an encryption key the
+// original data is dropped and reruns see the redacted values.
+func (e *FlowExecutor) seal(data, redacted map[string]any) string {
+	if e.sealer == nil || data == nil || sameMap(data, redacted) {
+		return ""
+	}
+	encoded, err := bson.Marshal(data)
+	if err == nil {
+		var sealed string
+		if sealed, err = e.sealer.Encrypt(encoded); err == nil {
+			return sealed
+		}
+	}
+	logger.Log.Warnw("Failed to seal redacted execution data", "error", err)
+	return ""
+}
+
+// unseal restores the data sealed for an execution read from the database,
+// so it can be run again with what it originally received
+func (e *FlowExecutor) unseal(execution *domain.Execution) {
+	execution.Input = e.unsealMap(execution.SealedInput, execution.Input)
+	for i := range execution.NodeLogs {
+		nodeLog := &execution.NodeLogs[i]
+		nodeLog.Input = e.unsealMap(nodeLog.SealedInput, nodeLog.Input)
+		nodeLog.Output = e.unsealMap(nodeLog.SealedOutput, nodeLog.Output)
+	}
+}
+
+// unsealMap decrypts sealed data, falling back to the redacted data if there
+// is none or it cannot be decrypted, e.g. after the key changed
+func (e *FlowExecutor) unsealMap(sealed string, redacted map[string]any) map[string]any {
+	if sealed == "" || e.sealer == nil {
+		return redacted
+	}
+	encoded, err := e.sealer.Decrypt(sealed)
+	if err == nil {
+		var data map[string]any
+		if err = bson.Unmarshal(encoded, &data); err == nil {
+			return data
+		}
+	}
+	logger.Log.Warnw("Failed to unseal execution data, using the redacted values", "error", err)
+	return redacted
+}
+
+// sameMap reports whether two maps are the same map, which is what redaction
+// returns when there was nothing to redact
+func sameMap(a, b map[string]any) bool {
+	return reflect.ValueOf(a).UnsafePointer() == reflect.ValueOf(b).UnsafePointer()
+}
+
+// RedactExecutions applies the redaction rules of their workflows to
+// executions read from the database, which may have been stored before the
+// rules changed
+func (e *FlowExecutor) RedactExecutions(ctx context.Context, executions []domain.Execution) {
+	rules := make(map[primitive.ObjectID]*redact.Rules)
+	for i := range executions {
+		workflowID := executions[i].WorkflowID
+		r, ok := rules[workflowID]
+		if !ok {
+			r = e.redaction
+			if workflow, err := e.workflowRepo.GetByID(ctx, workflowID); err == nil && workflow != nil {
+				r = e.redactionRules(ctx, workflow)
+			}
+			rules[workflowID] = r
+		}
+		executions[i] = *r.Execution(&executions[i])
+	}
+}
+
+// RedactExecution applies the redaction rules of its workflow to an
+// execution read from the database
+func (e *FlowExecutor) RedactExecution(ctx context.Context, execution *domain.Execution) *domain.Execution {
+	executions := []domain.Execution{*execution}
+	e.RedactExecutions(ctx, executions)
+	return &executions[0]
+}
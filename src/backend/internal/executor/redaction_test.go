@@ -0,0 +1,123 @@
+package executor
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/nodetl/nodetl/internal/domain"
+	"github.com/nodetl/nodetl/internal/redact"
+	"github.com/nodetl/nodetl/pkg/secrets"
+)
+
+// authorizationWorkflow routes requests with the expected Authorization
+// header to the "authorized" node and others to "rejected"
+func authorizationWorkflow() *domain.Workflow {
+	return &domain.Workflow{
+		Name: "authorization",
+		Nodes: []domain.Node{
+			{ID: "trigger", Type: domain.NodeTypeTrigger},
+			{ID: "check", Type: domain.NodeTypeCondition, Data: domain.NodeData{
+				Conditions: []domain.Condition{{
+					Field:    "_request.headers.Authorization",
+					Operator: "eq",
+					Value:    "Bearer s3cret",
+					OutputID: "true",
+				}},
+			}},
+			{ID: "authorized", Type: domain.NodeTypeCode, Data: domain.NodeData{Code: `"authorized"`}},
+			{ID: "rejected", Type: domain.NodeTypeCode, Data: domain.NodeData{Code: `"rejected"`}},
+		},
+		Edges: []domain.Edge{
+			{ID: "e1", Source: "trigger", Target: "check"},
+			{ID: "e2", Source: "check", Target: "authorized", SourceHandle: "true"},
+			{ID: "e3", Source: "check", Target: "rejected", SourceHandle: "false"},
+		},
+	}
+}
+
+func authorizedRequest() map[string]any {
+	return map[string]any{
+		"_request": map[string]any{
+			"headers": map[string]any{"Authorization": "Bearer s3cret"},
+		},
+		"orderId": "o-1",
+	}
+}
+
+func newRedactingExecutor(t *testing.T, workflow *domain.Workflow) (*FlowExecutor, *memExecutions) {
+	t.Helper()
+	e, executions := newTestExecutor(workflow)
+
+	rules, err := redact.New(redact.DefaultHeaders, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealer, err := secrets.NewCipher(strings.Repeat("ab", 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.redaction = rules
+	e.sealer = sealer
+	return e, executions
+}
+
+// authorization returns the Authorization header of a webhook input
+func authorization(input map[string]any) any {
+	request, _ := input["_request"].(map[string]any)
+	headers, _ := request["headers"].(map[string]any)
+	return headers["Authorization"]
+}
+
+// ranNode reports whether a node ran in a stored execution
+func ranNode(execution *domain.Execution, nodeID string) bool {
+	for _, nodeLog := range execution.NodeLogs {
+		if nodeLog.NodeID == nodeID {
+			return true
+		}
+	}
+	return false
+}
+
+func TestQueuedRunAndReplaySeeRedactedHeaders(t *testing.T) {
+	ctx := context.Background()
+	workflow := authorizationWorkflow()
+	e, executions := newRedactingExecutor(t, workflow)
+
+	// Queued run
+	jobs := &memJobs{}
+	pool := NewWorkerPool(e, jobs, 1, 0)
+	queued, err := pool.Submit(ctx, &ExecuteRequest{WorkflowID: workflow.ID, TriggerType: "webhook", Input: authorizedRequest()})
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	if !pool.runNext(ctx, "worker-1") {
+		t.Fatal("runNext() found no job")
+	}
+
+	original, _ := executions.GetByID(ctx, mustObjectID(t, queued.ExecutionID))
+	if original.Status != domain.ExecutionStatusCompleted {
+		t.Fatalf("queued run status = %s, error = %v", original.Status, original.Error)
+	}
+	if !ranNode(original, "authorized") || ranNode(original, "rejected") {
+		t.Fatalf("queued run took the wrong branch: %+v", original.NodeLogs)
+	}
+
+	// Replays of the whole execution and from the condition node
+	for _, fromNode := range []string{"", "check"} {
+		replay, err := e.Replay(ctx, original.ID, fromNode)
+		if err != nil {
+			t.Fatalf("Replay(%q) error = %v", fromNode, err)
+		}
+		replayed, _ := executions.GetByID(ctx, mustObjectID(t, replay.ExecutionID))
+		if !ranNode(replayed, "authorized") || ranNode(replayed, "rejected") {
+			t.Fatalf("Replay(%q) took the wrong branch: %+v", fromNode, replayed.NodeLogs)
+		}
+	}
+
+	for _, input := range executions.inputsWritten() {
+		if got := authorization(input); got != redact.Placeholder {
+			t.Fatalf("stored Authorization = %v, want it redacted", got)
+		}
+	}
+}
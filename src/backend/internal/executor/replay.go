@@ -0,0 +1,148 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nodetl/nodetl/internal/domain"
+	"github.com/nodetl/nodetl/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	// ErrExecutionNotFinished is returned when replaying an execution that is still queued or running
+	ErrExecutionNotFinished = errors.New("execution has not finished yet")
+	// ErrReplayNode is returned when an execution cannot be replayed from the requested node
+	ErrReplayNode = errors.New("cannot replay from node")
+)
+
+// Execution metadata keys linking a replay to the execution it reprocesses
+const (
+	metaReplayOf       = "replayOf"
+	metaReplayFromNode = "replayFromNode"
+)
+
+// Replay re-runs a finished execution against the current workflow
+// definition. Without fromNode the stored trigger input is run from the
+// trigger that started the original execution. With fromNode the run starts
+// at that node, using the input recorded for it in the original node logs.
+func (e *FlowExecutor) Replay(ctx context.Context, executionID primitive.ObjectID, fromNode string) (*ExecuteResult, error) {
+	original, err := e.executionRepo.GetByID(ctx, executionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get execution: %w", err)
+	}
+	if original == nil {
+		return nil, ErrExecutionNotFound
+	}
+	if original.Status == domain.ExecutionStatusPending || original.Status == domain.ExecutionStatusRunning {
+		return nil, ErrExecutionNotFinished
+	}
+	e.unseal(original)
+
+	req := &ExecuteRequest{
+		WorkflowID:  original.WorkflowID,
+		TriggerType: "replay",
+		Environment: original.Environment,
+		Input:       original.Input,
+		Metadata:    replayMetadata(original, fromNode),
+	}
+	if path, ok := original.Metadata["endpoint"].(string); ok {
+		req.TriggerPath = path
+	}
+	if nodeID, ok := original.Metadata["triggerNodeId"].(string); ok {
+		req.TriggerNodeID = nodeID
+	}
+
+	if fromNode == "" {
+		return e.Execute(ctx, req)
+	}
+
+	// Take the input of the node's last run, so nodes inside a loop body
+	// resume with the item they failed on
+	recordedAt := -1
+	for i := range original.NodeLogs {
+		if original.NodeLogs[i].NodeID == fromNode {
+			recordedAt = i
+		}
+	}
+	var recorded *domain.NodeExecutionLog
+	if recordedAt >= 0 {
+		recorded = &original.NodeLogs[recordedAt]
+	}
+	if recorded == nil {
+		return nil, fmt.Errorf("%w %s: it did not run in execution %s", ErrReplayNode, fromNode, executionID.Hex())
+	}
+	if original.PayloadsPurged {
+		return nil, fmt.Errorf("%w %s: the node inputs of execution %s were purged by its retention policy", ErrReplayNode, fromNode, executionID.Hex())
+	}
+
+	// Check the node still exists before recording a new execution
+	workflow, err := e.workflowRepo.GetByID(ctx, original.WorkflowID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow: %w", err)
+	}
+	if workflow != nil && findNodeByID(workflow.Nodes, fromNode) == nil {
+		return nil, fmt.Errorf("%w %s: it no longer exists in the workflow", ErrReplayNode, fromNode)
+	}
+
+	workflow, execution, err := e.createExecution(ctx, req, domain.ExecutionStatusRunning)
+	if err != nil {
+		return nil, err
+	}
+	entry := findNodeByID(workflow.Nodes, fromNode)
+	if entry == nil {
+		err := fmt.Errorf("%w %s: it no longer exists in the workflow", ErrReplayNode, fromNode)
+		execution.Status = domain.ExecutionStatusFailed
+		execution.Error = &domain.ExecutionError{NodeID: fromNode, Message: err.Error()}
+		e.publishFinished(e.saveExecution(ctx, execution, e.redactionRules(ctx, workflow)))
+		return nil, err
+	}
+
+	logger.Log.Infow("Replaying workflow execution",
+		"workflowId", workflow.ID.Hex(),
+		"executionId", execution.ID.Hex(),
+		"replayOf", executionID.Hex(),
+		"fromNode", fromNode,
+	)
+
+	return e.runFrom(ctx, workflow, execution, entry, recorded.Input, completedOutputs(original.NodeLogs[:recordedAt]))
+}
+
+// completedOutputs collects the outputs of the completed nodes in a list of
+// node logs, so a replay from a node can still read what ran before it
+func completedOutputs(logs []domain.NodeExecutionLog) map[string]any {
+	outputs := make(map[string]any)
+	for _, log := range logs {
+		if log.Status == domain.ExecutionStatusCompleted {
+			outputs[log.NodeID] = log.Output
+		}
+	}
+	return outputs
+}
+
+// replayMetadata carries the original metadata over to the replay, except
+// for identifiers that belong to the original run
+func replayMetadata(original *domain.Execution, fromNode string) map[string]any {
+	metadata := make(map[string]any, len(original.Metadata)+2)
+	for k, v := range original.Metadata {
+		if k == metaTraceID || k == metaTraceparent || k == "requestId" {
+			continue
+		}
+		metadata[k] = v
+	}
+	metadata[metaReplayOf] = original.ID.Hex()
+	if fromNode != "" {
+		metadata[metaReplayFromNode] = fromNode
+	}
+	return metadata
+}
+
+func findNodeByID(nodes []domain.Node, id string) *domain.Node {
+	for i := range nodes {
+		if nodes[i].ID == id {
+			return &nodes[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nodetl/nodetl/internal/domain"
+)
+
+// countRuns returns how often a node ran in an execution
+func countRuns(execution *domain.Execution, nodeID string) int {
+	count := 0
+	for _, nodeLog := range execution.NodeLogs {
+		if nodeLog.NodeID == nodeID {
+			count++
+		}
+	}
+	return count
+}
+
+func TestReplayFromLoopBodyDoesNotRerunLoop(t *testing.T) {
+	ctx := context.Background()
+	workflow := &domain.Workflow{
+		Name: "loop",
+		Nodes: []domain.Node{
+			{ID: "trigger", Type: domain.NodeTypeTrigger},
+			{ID: "loop", Type: domain.NodeTypeLoop, Data: domain.NodeData{LoopType: "forEach", LoopArrayPath: "items"}},
+			{ID: "body", Type: domain.NodeTypeCode, Data: domain.NodeData{Code: `"processed"`}},
+			{ID: "after", Type: domain.NodeTypeCode, Data: domain.NodeData{Code: `"done"`}},
+		},
+		Edges: []domain.Edge{
+			{ID: "e1", Source: "trigger", Target: "loop"},
+			{ID: "e2", Source: "loop", Target: "body", SourceHandle: "item"},
+			{ID: "e3", Source: "body", Target: "loop"},
+			{ID: "e4", Source: "loop", Target: "after", SourceHandle: "done"},
+		},
+	}
+	e, executions := newTestExecutor(workflow)
+
+	result, err := e.Execute(ctx, &ExecuteRequest{WorkflowID: workflow.ID, Input: map[string]any{"items": []any{"a", "b", "c"}}})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	original, _ := executions.GetByID(ctx, mustObjectID(t, result.ExecutionID))
+	if original.Status != domain.ExecutionStatusCompleted || countRuns(original, "body") != 3 {
+		t.Fatalf("original run: status %s, body ran %d times", original.Status, countRuns(original, "body"))
+	}
+
+	replay, err := e.Replay(ctx, original.ID, "body")
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	replayed, _ := executions.GetByID(ctx, mustObjectID(t, replay.ExecutionID))
+	if replayed.Status != domain.ExecutionStatusCompleted {
+		t.Fatalf("replay status = %s, error = %v", replayed.Status, replayed.Error)
+	}
+	if runs := countRuns(replayed, "loop"); runs != 0 {
+		t.Errorf("replay ran the loop node %d times, want 0", runs)
+	}
+	if runs := countRuns(replayed, "body"); runs != 1 {
+		t.Errorf("replay ran the body %d times, want 1", runs)
+	}
+}
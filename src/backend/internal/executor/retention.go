@@ -0,0 +1,164 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nodetl/nodetl/internal/domain"
+	"github.com/nodetl/nodetl/internal/repository"
+	"github.com/nodetl/nodetl/pkg/logger"
+)
+
+// retentionPolicy returns the retention policy of a workflow: its own
+// settings, then its project's, then the server defaults
+func (e *FlowExecutor) retentionPolicy(workflow *domain.Workflow, project *domain.Project) domain.RetentionPolicy {
+	var workflowPolicy, projectPolicy *domain.RetentionPolicy
+	if workflow.Settings != nil {
+		workflowPolicy = workflow.Settings.Retention
+	}
+	if project != nil {
+		projectPolicy = project.Retention
+	}
+	return domain.ResolveRetention(workflowPolicy, projectPolicy, &e.retention)
+}
+
+// purgePayloads drops the node inputs and outputs of an execution
+func purgePayloads(execution *domain.Execution) {
+	for i := range execution.NodeLogs {
+		execution.NodeLogs[i].Input = nil
+		execution.NodeLogs[i].Output = nil
+	}
+	execution.PayloadsPurged = true
+}
+
+// Purger applies the retention policies of all workflows to their stored
+// executions. The TTL index on expires_at removes executions once their
+// maximum age passes; the purger also enforces the maximum count, applies
+// policies that changed after executions were stored and drops the payloads
+// of completed executions that should not keep them.
+type Purger struct {
+	executor *FlowExecutor
+	interval time.Duration
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// PurgeResult counts what a purge pass removed
+type PurgeResult struct {
+	Deleted int64 // Executions deleted
+	Purged  int64 // Executions whose node payloads were dropped
+}
+
+// NewPurger creates a new purger
+func NewPurger(executor *FlowExecutor, interval time.Duration) *Purger {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return &Purger{
+		executor: executor,
+		interval: interval,
+	}
+}
+
+// Start applies the retention policies now and then periodically in the
+// background
+func (p *Purger) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+
+	p.wg.Add(1)
+	go p.loop(ctx)
+
+	logger.Log.Infow("Execution purger started", "interval", p.interval.String())
+}
+
+// Stop stops the purger and waits for the current pass to finish
+func (p *Purger) Stop() {
+	if p.cancel == nil {
+		return
+	}
+	p.cancel()
+	p.wg.Wait()
+	logger.Log.Info("Execution purger stopped")
+}
+
+func (p *Purger) loop(ctx context.Context) {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := p.Purge(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.Log.Errorw("Failed to purge executions", "error", err)
+		}
+		if result.Deleted > 0 || result.Purged > 0 {
+			logger.Log.Infow("Purged executions", "deleted", result.Deleted, "payloadsPurged", result.Purged)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge applies the retention policy of every workflow once
+func (p *Purger) Purge(ctx context.Context) (PurgeResult, error) {
+	var result PurgeResult
+
+	workflows, _, err := p.executor.workflowRepo.GetAll(ctx, repository.WorkflowFilter{})
+	if err != nil {
+		return result, fmt.Errorf("failed to list workflows: %w", err)
+	}
+
+	projects := make(map[string]*domain.Project)
+	for i := range workflows {
+		workflow := &workflows[i]
+
+		project, ok := projects[workflow.ProjectID]
+		if !ok {
+			if project, err = p.executor.workflowProject(ctx, workflow); err != nil {
+				return result, err
+			}
+			projects[workflow.ProjectID] = project
+		}
+
+		if err := p.purgeWorkflow(ctx, workflow, p.executor.retentionPolicy(workflow, project), &result); err != nil {
+			return result, fmt.Errorf("failed to purge executions of workflow %s: %w", workflow.ID.Hex(), err)
+		}
+	}
+	return result, nil
+}
+
+// purgeWorkflow applies a retention policy to the executions of one workflow
+func (p *Purger) purgeWorkflow(ctx context.Context, workflow *domain.Workflow, policy domain.RetentionPolicy, result *PurgeResult) error {
+	repo := p.executor.executionRepo
+
+	if maxAge := policy.MaxAge(); maxAge > 0 {
+		deleted, err := repo.DeleteFinishedBefore(ctx, workflow.ID, time.Now().Add(-maxAge))
+		if err != nil {
+			return err
+		}
+		result.Deleted += deleted
+	}
+	if policy.MaxCount > 0 {
+		deleted, err := repo.DeleteFinishedBeyond(ctx, workflow.ID, policy.MaxCount)
+		if err != nil {
+			return err
+		}
+		result.Deleted += deleted
+	}
+	if !policy.KeepsSuccessfulPayloads() {
+		purged, err := repo.PurgePayloads(ctx, workflow.ID)
+		if err != nil {
+			return err
+		}
+		result.Purged += purged
+	}
+	return nil
+}
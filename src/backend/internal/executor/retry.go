@@ -0,0 +1,142 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nodetl/nodetl/internal/domain"
+	"github.com/nodetl/nodetl/internal/node"
+)
+
+const defaultRetryDelay = time.Second
+
+// defaultRetryStatuses are the HTTP statuses retried when a policy lists none
+var defaultRetryStatuses = []int{408, 429, 500, 502, 503, 504}
+
+// executeWithRetry runs a node, retrying it according to its retry policy.
+// Without a policy the node runs exactly once and no attempts are recorded.
+func executeWithRetry(ctx context.Context, executor node.NodeExecutor, execCtx *node.ExecutionContext, nodeData domain.NodeData) (*node.ExecutionResult, []domain.NodeAttempt, error) {
+	policy := nodeData.Retry
+	if policy == nil || policy.MaxAttempts <= 1 {
+		result, _, err := executeOnce(ctx, executor, execCtx, nodeData)
+		return result, nil, err
+	}
+
+	var attempts []domain.NodeAttempt
+	for attempt := 1; ; attempt++ {
+		startTime := time.Now()
+		result, settled, err := executeOnce(ctx, executor, execCtx, nodeData)
+
+		record := domain.NodeAttempt{
+			Attempt:    attempt,
+			StartedAt:  startTime,
+			Duration:   time.Since(startTime).Milliseconds(),
+			StatusCode: resultStatusCode(result),
+		}
+		failure := err
+		if failure == nil && result != nil && result.Error != nil {
+			failure = result.Error
+		}
+		if failure != nil {
+			msg := failure.Error()
+			record.Error = &msg
+		} else if record.StatusCode >= 400 {
+			msg := fmt.Sprintf("received HTTP status %d", record.StatusCode)
+			record.Error = &msg
+		}
+		attempts = append(attempts, record)
+
+		if attempt >= policy.MaxAttempts || !shouldRetry(policy, failure, record.StatusCode) {
+			return result, attempts, err
+		}
+
+		// Wait before the next attempt unless the execution is cancelled
+		select {
+		case <-time.After(retryDelay(policy, attempt)):
+		case <-ctx.Done():
+			return result, attempts, err
+		}
+
+		// A timed out attempt of a node that ignores its context may still
+		// be running; its side effects must not overlap with the next one
+		select {
+		case <-settled:
+		case <-ctx.Done():
+			return result, attempts, err
+		}
+	}
+}
+
+// shouldRetry decides whether a failed attempt is retryable under the policy
+func shouldRetry(policy *domain.RetryPolicy, err error, statusCode int) bool {
+	if err != nil {
+		if len(policy.RetryOnErrors) == 0 {
+			return true
+		}
+		msg := err.Error()
+		for _, fragment := range policy.RetryOnErrors {
+			if strings.Contains(msg, fragment) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if statusCode == 0 {
+		return false
+	}
+	statuses := policy.RetryOnStatus
+	if len(statuses) == 0 {
+		statuses = defaultRetryStatuses
+	}
+	for _, status := range statuses {
+		if status == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// retryDelay returns how long to wait after the given attempt
+func retryDelay(policy *domain.RetryPolicy, attempt int) time.Duration {
+	base := defaultRetryDelay
+	if policy.InitialDelayMs > 0 {
+		base = time.Duration(policy.InitialDelayMs) * time.Millisecond
+	}
+
+	delay := base
+	switch policy.Backoff {
+	case domain.BackoffLinear:
+		delay = base * time.Duration(attempt)
+	case domain.BackoffExponential:
+		// Cap the shift so large attempt counts cannot overflow
+		shift := attempt - 1
+		if shift > 20 {
+			shift = 20
+		}
+		delay = base * time.Duration(1<<shift)
+	}
+
+	if policy.MaxDelayMs > 0 {
+		if maxDelay := time.Duration(policy.MaxDelayMs) * time.Millisecond; delay > maxDelay {
+			delay = maxDelay
+		}
+	}
+	return delay
+}
+
+// resultStatusCode extracts the HTTP status code reported by nodes such as HTTPNode
+func resultStatusCode(result *node.ExecutionResult) int {
+	if result == nil || result.Output == nil {
+		return 0
+	}
+	switch v := result.Output["statusCode"].(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	}
+	return 0
+}
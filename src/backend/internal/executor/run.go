@@ -0,0 +1,566 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nodetl/nodetl/internal/domain"
+	"github.com/nodetl/nodetl/internal/metrics"
+	"github.com/nodetl/nodetl/internal/node"
+	"github.com/nodetl/nodetl/internal/redact"
+	"github.com/nodetl/nodetl/pkg/logger"
+)
+
+// executionRun holds the state of a single workflow run over the node graph.
+// Nodes are executed as soon as all of their reachable upstream branches have
+// resolved, so independent branches run concurrently.
+type executionRun struct {
+	*runShared
+
+	// reachable contains the nodes that can be reached from the entry node.
+	// Incoming edges from any other node are ignored when joining.
+	reachable map[string]bool
+	// backEdges close a cycle; they are never followed or waited for
+	backEdges map[domain.Edge]bool
+	// boundary is the node a branch run was started from (e.g. a loop).
+	// Edges leading back into it end the branch instead of re-running it.
+	boundary string
+
+	mu        sync.Mutex
+	wg        sync.WaitGroup
+	cancel    context.CancelFunc
+	err       error
+	joins     map[string]*joinState
+	terminals []terminalOutput
+}
+
+// runShared is the state shared by a run and all branch runs started from it
+type runShared struct {
+	executor     *FlowExecutor
+	workflow     *domain.Workflow
+	execution    *domain.Execution
+	graph        *NodeGraph
+	triggerInput map[string]any
+	traceID      string
+	environment  string
+	variables    map[string]any          // Workflow variables overridden by the environment's
+	credentials  node.CredentialResolver // Resolves credentials through the environment's bindings
+	redaction    *redact.Rules           // Applied to node logs and events before they leave the run
+
+	logMu sync.Mutex
+
+	// outputs holds the latest output of every node that completed in this
+	// execution, keyed by node ID, so later nodes can read it
+	outputsMu sync.RWMutex
+	outputs   map[string]any
+}
+
+// joinState tracks the incoming branches of a node
+type joinState struct {
+	expected      int
+	arrived       int
+	inputs        []branchInput
+	previousInput map[string]any
+	execErr       *node.ExecutionError
+}
+
+// branchInput is the data delivered over a single incoming edge
+type branchInput struct {
+	sourceID string
+	order    int // Position of the edge among the target's incoming edges
+	output   map[string]any
+}
+
+// terminalOutput is the output of a node that has no further edges to follow
+type terminalOutput struct {
+	nodeID   string
+	nodeType string
+	output   map[string]any
+}
+
+// newExecutionRun prepares a run in the given environment, which may be nil,
+// redacting what it stores and publishes with the given rules. outputs seeds
+// the node outputs, e.g. with the nodes that ran before the node a replay
+// starts from, and may be nil.
+func newExecutionRun(e *FlowExecutor, workflow *domain.Workflow, execution *domain.Execution, environment *domain.Environment, redaction *redact.Rules, graph *NodeGraph, triggerInput map[string]any, outputs map[string]any) *executionRun {
+	traceID := ""
+	if execution.Metadata != nil {
+		if tid, ok := execution.Metadata[metaTraceID].(string); ok {
+			traceID = tid
+		}
+	}
+	if outputs == nil {
+		outputs = make(map[string]any)
+	}
+
+	return &executionRun{
+		runShared: &runShared{
+			executor:     e,
+			workflow:     workflow,
+			execution:    execution,
+			graph:        graph,
+			triggerInput: triggerInput,
+			traceID:      traceID,
+			environment:  execution.Environment,
+			variables:    environmentVariables(workflow, environment),
+			credentials:  withEnvironment(e.credentials, environment),
+			redaction:    redaction,
+			outputs:      outputs,
+		},
+		joins: make(map[string]*joinState),
+	}
+}
+
+// run executes the graph starting from the entry node and blocks until every
+// branch has finished. It returns the final output of the workflow.
+func (r *executionRun) run(ctx context.Context, entry *domain.Node, input map[string]any) (map[string]any, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	r.cancel = cancel
+
+	r.reachable = r.graph.ReachableFrom(entry.ID)
+	r.backEdges = r.graph.entryBackEdges(entry.ID)
+
+	r.wg.Add(1)
+	go r.executeNode(ctx, entry, input, nil, nil)
+	r.wg.Wait()
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	// Branches stop quietly when the caller cancels, so report why
+	if ctx.Err() != nil {
+		return nil, context.Cause(ctx)
+	}
+	return r.finalOutput(), nil
+}
+
+// runBranch executes the part of the graph connected to one output port of the
+// boundary node and returns its output. The boundary node itself is not re-run.
+func (r *executionRun) runBranch(ctx context.Context, port string, input map[string]any, previousInput map[string]any) (map[string]any, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	r.cancel = cancel
+
+	boundary := r.graph.Nodes[r.boundary]
+	starts := []string{}
+	for _, edge := range r.graph.Edges[boundary.ID] {
+		if edge.SourceHandle == port {
+			starts = append(starts, edge.Target)
+		}
+	}
+	if len(starts) == 0 {
+		// Nothing connected to the port, pass the input through
+		return input, nil
+	}
+
+	r.reachable = r.graph.reachableFrom(starts, boundary.ID)
+	r.backEdges = r.graph.BackEdges(boundary.ID)
+
+	r.dispatch(ctx, boundary, input, previousInput, port, nil)
+	r.wg.Wait()
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.finalOutput(), nil
+}
+
+// fail records the first error of the run and stops all other branches
+func (r *executionRun) fail(err error) {
+	r.mu.Lock()
+	if r.err == nil {
+		r.err = err
+	}
+	r.mu.Unlock()
+	r.cancel()
+}
+
+// executeNode runs a single node and routes its result. upstreamErr is the
+// error being handled on this path, if the node sits behind an error port.
+func (r *executionRun) executeNode(ctx context.Context, currentNode *domain.Node, input map[string]any, previousInput map[string]any, upstreamErr *node.ExecutionError) {
+	defer r.wg.Done()
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	startTime := time.Now()
+
+	// Get executor for this node type
+	executor, ok := r.executor.nodeRegistry.Get(currentNode.Type)
+	if !ok {
+		r.fail(fmt.Errorf("no executor found for node type: %s", currentNode.Type))
+		return
+	}
+
+	nodeData := r.executor.prepareNodeData(ctx, r.workflow, currentNode)
+
+	// Create execution context
+	execCtx := &node.ExecutionContext{
+		WorkflowID:    r.workflow.ID.Hex(),
+		ExecutionID:   r.execution.ID.Hex(),
+		NodeID:        currentNode.ID,
+		TraceID:       r.traceID,
+		Input:         input,
+		PreviousInput: previousInput,
+		TriggerInput:  r.triggerInput,
+		Environment:   r.environment,
+		Variables:     r.variables,
+		PreviousData:  r.nodeOutputs(),
+		Error:         upstreamErr,
+		Branches:      &branchRunner{parent: r, node: currentNode, input: input},
+		Workflows:     &workflowRunner{run: r, nodeID: currentNode.ID},
+		Credentials:   r.credentials,
+	}
+
+	r.publishNode(domain.ExecutionEvent{
+		Type:      domain.EventNodeStarted,
+		Status:    domain.ExecutionStatusRunning,
+		Timestamp: startTime,
+	}, currentNode)
+
+	// Execute node, retrying it if it has a retry policy
+	nodeCtx, span := startNodeSpan(ctx, currentNode)
+	result, attempts, err := executeWithRetry(nodeCtx, executor, execCtx, nodeData)
+
+	// Record node execution log
+	nodeLog := domain.NodeExecutionLog{
+		NodeID:    currentNode.ID,
+		NodeType:  currentNode.Type,
+		NodeLabel: currentNode.Label,
+		Input:     input,
+		StartedAt: startTime,
+		Attempts:  attempts,
+	}
+
+	now := time.Now()
+	nodeLog.CompletedAt = &now
+	nodeLog.Duration = time.Since(startTime).Milliseconds()
+
+	if err == nil && result != nil && result.Error != nil {
+		err = result.Error
+	}
+
+	if err != nil {
+		nodeLog.Status = domain.ExecutionStatusFailed
+		if context.Cause(ctx) == errExecutionCancelled {
+			nodeLog.Status = domain.ExecutionStatusCancelled
+		}
+		errMsg := err.Error()
+		nodeLog.Error = &errMsg
+	} else {
+		nodeLog.Status = domain.ExecutionStatusCompleted
+		nodeLog.Output = result.Output
+		r.recordOutput(currentNode.ID, result.Output)
+	}
+
+	if result != nil {
+		nodeLog.Logs = result.Logs
+	}
+
+	endNodeSpan(span, nodeLog)
+	r.recordNodeLog(ctx, nodeLog)
+
+	event := domain.ExecutionEvent{
+		Type:     domain.EventNodeCompleted,
+		Status:   nodeLog.Status,
+		Output:   r.redaction.Map(nodeLog.Output),
+		Duration: nodeLog.Duration,
+	}
+	if err != nil {
+		event.Type = domain.EventNodeFailed
+		event.Error = &domain.ExecutionError{NodeID: currentNode.ID, Message: err.Error()}
+	}
+	r.publishNode(event, currentNode)
+
+	if err != nil {
+		// Failures caused by the run being stopped are never handled
+		if ctx.Err() == nil && r.hasErrorEdge(currentNode) {
+			execErr := toNodeError(err)
+			execErr.NodeID = currentNode.ID
+			r.routeError(ctx, currentNode, result, input, execErr)
+			return
+		}
+		r.fail(newNodeFailure(currentNode.ID, err))
+		return
+	}
+
+	// Error statuses reported by nodes such as HTTP are handled like failures
+	// when the error port is connected
+	if result.NextPort == errorPort && r.hasErrorEdge(currentNode) {
+		if status := resultStatusCode(result); status >= 400 {
+			execErr := node.NewStatusError(status, result.Output["body"])
+			execErr.NodeID = currentNode.ID
+			r.routeError(ctx, currentNode, result, input, execErr)
+			return
+		}
+	}
+
+	r.dispatch(ctx, currentNode, result.Output, input, result.NextPort, upstreamErr)
+}
+
+// recordNodeLog adds a node log to the execution and persists it straight
+// away, so the progress of a run survives a crash and can be read while it
+// is running. The log is written even if the run is being cancelled.
+func (r *executionRun) recordNodeLog(ctx context.Context, nodeLog domain.NodeExecutionLog) {
+	r.logMu.Lock()
+	r.execution.NodeLogs = append(r.execution.NodeLogs, nodeLog)
+	r.logMu.Unlock()
+	metrics.ObserveNode(nodeLog.NodeType, string(nodeLog.Status), time.Duration(nodeLog.Duration)*time.Millisecond)
+
+	if err := r.executor.executionRepo.AppendNodeLog(context.WithoutCancel(ctx), r.execution.ID, r.executor.storedNodeLog(nodeLog, r.redaction)); err != nil {
+		logger.Log.Warnw("Failed to persist node log",
+			"executionId", r.execution.ID.Hex(),
+			"nodeId", nodeLog.NodeID,
+			"error", err,
+		)
+	}
+}
+
+// recordOutput remembers the output of a completed node. Nodes that run
+// several times, e.g. inside a loop body, keep their latest output.
+func (s *runShared) recordOutput(nodeID string, output map[string]any) {
+	s.outputsMu.Lock()
+	s.outputs[nodeID] = output
+	s.outputsMu.Unlock()
+}
+
+// nodeOutputs returns a copy of the node outputs recorded so far
+func (s *runShared) nodeOutputs() map[string]any {
+	s.outputsMu.RLock()
+	defer s.outputsMu.RUnlock()
+	outputs := make(map[string]any, len(s.outputs))
+	for id, output := range s.outputs {
+		outputs[id] = output
+	}
+	return outputs
+}
+
+// publishNode publishes a progress event for a node of this run
+func (r *executionRun) publishNode(event domain.ExecutionEvent, currentNode *domain.Node) {
+	event.ExecutionID = r.execution.ID.Hex()
+	event.WorkflowID = r.workflow.ID.Hex()
+	event.NodeID = currentNode.ID
+	event.NodeType = currentNode.Type
+	event.NodeLabel = currentNode.Label
+	r.executor.events.Publish(event)
+}
+
+// hasErrorEdge reports whether the node's error port leads anywhere in this run
+func (r *executionRun) hasErrorEdge(currentNode *domain.Node) bool {
+	for _, edge := range r.graph.Edges[currentNode.ID] {
+		if edge.SourceHandle == errorPort && r.follows(edge) {
+			return true
+		}
+	}
+	return false
+}
+
+// routeError sends a failed node's error down its error port only. The
+// handlers receive the node's output, or its input when it produced none,
+// with the error under "error", and see it as ExecutionContext.Error.
+func (r *executionRun) routeError(ctx context.Context, currentNode *domain.Node, result *node.ExecutionResult, input map[string]any, execErr *node.ExecutionError) {
+	source := input
+	if result != nil && result.Output != nil {
+		source = result.Output
+	}
+	output := make(map[string]any, len(source)+1)
+	for k, v := range source {
+		output[k] = v
+	}
+	output["error"] = execErr.ToMap()
+
+	for _, edge := range r.graph.Edges[currentNode.ID] {
+		if !r.follows(edge) {
+			continue
+		}
+		if edge.SourceHandle == errorPort {
+			r.deliver(ctx, edge, output, input, execErr, true)
+		} else {
+			r.deliver(ctx, edge, nil, nil, nil, false)
+		}
+	}
+}
+
+// dispatch follows every outgoing edge matching the output port and marks the
+// remaining edges as skipped, starting any downstream node that became ready.
+func (r *executionRun) dispatch(ctx context.Context, currentNode *domain.Node, output map[string]any, input map[string]any, port string, execErr *node.ExecutionError) {
+	followed := false
+	for _, edge := range r.graph.Edges[currentNode.ID] {
+		if !r.follows(edge) {
+			continue
+		}
+		if edgeMatchesPort(edge, port) {
+			followed = true
+			r.deliver(ctx, edge, output, input, execErr, true)
+		} else {
+			r.deliver(ctx, edge, nil, nil, nil, false)
+		}
+	}
+
+	if !followed {
+		r.mu.Lock()
+		r.terminals = append(r.terminals, terminalOutput{
+			nodeID:   currentNode.ID,
+			nodeType: currentNode.Type,
+			output:   output,
+		})
+		r.mu.Unlock()
+	}
+}
+
+// deliver resolves one incoming edge of the target node. Once every reachable
+// incoming edge is resolved the node either runs with the merged inputs of its
+// active branches, or is skipped when none of them fired.
+func (r *executionRun) deliver(ctx context.Context, edge domain.Edge, output map[string]any, previousInput map[string]any, execErr *node.ExecutionError, active bool) {
+	target := r.graph.Nodes[edge.Target]
+
+	r.mu.Lock()
+	state, ok := r.joins[target.ID]
+	if !ok {
+		state = &joinState{expected: r.expectedInputs(target.ID)}
+		r.joins[target.ID] = state
+	}
+	state.arrived++
+	if active {
+		state.inputs = append(state.inputs, branchInput{sourceID: edge.Source, order: r.graph.inEdgeIndex(edge), output: output})
+		state.previousInput = previousInput
+		if state.execErr == nil {
+			state.execErr = execErr
+		}
+	}
+	ready := state.arrived == state.expected
+	r.mu.Unlock()
+
+	if !ready {
+		return
+	}
+
+	if len(state.inputs) == 0 {
+		// Every upstream branch was skipped, so this node is skipped as well
+		for _, next := range r.graph.Edges[target.ID] {
+			if r.follows(next) {
+				r.deliver(ctx, next, nil, nil, nil, false)
+			}
+		}
+		return
+	}
+
+	input := mergeInputs(target.Data.MergeMode, state.inputs)
+	r.wg.Add(1)
+	go r.executeNode(ctx, target, input, state.previousInput, state.execErr)
+}
+
+// follows reports whether an edge is part of this run
+func (r *executionRun) follows(edge domain.Edge) bool {
+	if _, ok := r.graph.Nodes[edge.Target]; !ok {
+		return false
+	}
+	return r.reachable[edge.Target] && !r.backEdges[edge]
+}
+
+// expectedInputs counts the incoming edges of a node that can actually fire
+func (r *executionRun) expectedInputs(nodeID string) int {
+	count := 0
+	for _, edge := range r.graph.InEdges[nodeID] {
+		if r.backEdges[edge] {
+			continue
+		}
+		if r.reachable[edge.Source] || edge.Source == r.boundary {
+			count++
+		}
+	}
+	return count
+}
+
+// finalOutput picks the workflow output from the terminal nodes. A response
+// node always wins; with several other leaves their outputs are keyed by node ID.
+func (r *executionRun) finalOutput() map[string]any {
+	if len(r.terminals) == 1 {
+		return r.terminals[0].output
+	}
+
+	for i := len(r.terminals) - 1; i >= 0; i-- {
+		if r.terminals[i].nodeType == domain.NodeTypeResponse {
+			return r.terminals[i].output
+		}
+	}
+
+	if len(r.terminals) == 0 {
+		return nil
+	}
+
+	output := make(map[string]any, len(r.terminals))
+	for _, t := range r.terminals {
+		output[t.nodeID] = t.output
+	}
+	return output
+}
+
+// branchRunner lets a node run the part of the graph connected to one of its
+// output ports, e.g. the body of a loop
+type branchRunner struct {
+	parent *executionRun
+	node   *domain.Node
+	input  map[string]any
+}
+
+// RunBranch implements node.BranchRunner
+func (b *branchRunner) RunBranch(ctx context.Context, port string, input map[string]any) (map[string]any, error) {
+	branch := &executionRun{
+		runShared: b.parent.runShared,
+		boundary:  b.node.ID,
+		joins:     make(map[string]*joinState),
+	}
+	return branch.runBranch(ctx, port, input, b.input)
+}
+
+// edgeMatchesPort checks whether an edge leaves from the given output port.
+// Edges without a source handle and results without a port always match.
+func edgeMatchesPort(edge domain.Edge, port string) bool {
+	return edge.SourceHandle == "" || port == "" || edge.SourceHandle == port
+}
+
+// mergeInputs combines the outputs of several upstream branches
+func mergeInputs(mode string, inputs []branchInput) map[string]any {
+	if len(inputs) == 1 && mode != domain.MergeModeByNode {
+		return inputs[0].output
+	}
+
+	// Branches arrive in any order, so merge them in the order of their edges
+	// to resolve colliding keys the same way on every run
+	sorted := append([]branchInput(nil), inputs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].order < sorted[j].order
+	})
+
+	merged := make(map[string]any)
+	for _, in := range sorted {
+		if mode == domain.MergeModeByNode {
+			merged[in.sourceID] = in.output
+			continue
+		}
+		deepMerge(merged, in.output)
+	}
+	return merged
+}
+
+// deepMerge copies src into dst, merging nested objects instead of replacing them
+func deepMerge(dst, src map[string]any) {
+	for key, value := range src {
+		srcMap, srcIsMap := value.(map[string]any)
+		dstMap, dstIsMap := dst[key].(map[string]any)
+		if srcIsMap && dstIsMap {
+			merged := make(map[string]any, len(dstMap)+len(srcMap))
+			deepMerge(merged, dstMap)
+			deepMerge(merged, srcMap)
+			dst[key] = merged
+			continue
+		}
+		dst[key] = value
+	}
+}
@@ -0,0 +1,29 @@
+package executor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeInputsResolvesCollisionsByEdgeOrder(t *testing.T) {
+	first := branchInput{sourceID: "a", order: 0, output: map[string]any{
+		"status":   "from a",
+		"customer": map[string]any{"id": "c-1", "tier": "gold"},
+	}}
+	second := branchInput{sourceID: "b", order: 1, output: map[string]any{
+		"status":   "from b",
+		"customer": map[string]any{"tier": "silver"},
+	}}
+	want := map[string]any{
+		"status":   "from b",
+		"customer": map[string]any{"id": "c-1", "tier": "silver"},
+	}
+
+	// Whichever branch finishes first, the later edge wins
+	for _, arrival := range [][]branchInput{{first, second}, {second, first}} {
+		got := mergeInputs("", arrival)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("mergeInputs(%s, %s) = %v, want %v", arrival[0].sourceID, arrival[1].sourceID, got, want)
+		}
+	}
+}
@@ -0,0 +1,332 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nodetl/nodetl/internal/domain"
+	"github.com/nodetl/nodetl/internal/node"
+	"github.com/nodetl/nodetl/internal/repository"
+	"github.com/nodetl/nodetl/pkg/logger"
+	"github.com/robfig/cron/v3"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// scheduleLeaseTTL is how long a schedule run holds its lease without
+// renewing it, and so how soon another instance takes over after a crash
+const scheduleLeaseTTL = time.Minute
+
+// Scheduler runs active workflows whose trigger nodes have a cron schedule.
+// Schedules are re-synced when workflows are activated or deactivated and
+// periodically reloaded so changes made on other instances are picked up.
+// Every instance may run a scheduler: each tick takes a lease in Mongo, so
+// it runs on one instance only.
+type Scheduler struct {
+	executor     *FlowExecutor
+	workflowRepo repository.WorkflowRepository
+	leaseRepo    repository.ScheduleLeaseRepository
+	instanceID   string
+	syncInterval time.Duration
+	cron         *cron.Cron
+
+	mu        sync.Mutex
+	workflows map[primitive.ObjectID]*scheduledWorkflow
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// scheduledWorkflow holds the cron entries registered for one workflow
+type scheduledWorkflow struct {
+	specs   map[string]string // trigger node ID -> schedule spec
+	entries []cron.EntryID
+}
+
+// NewScheduler creates a new scheduler
+func NewScheduler(executor *FlowExecutor, workflowRepo repository.WorkflowRepository, leaseRepo repository.ScheduleLeaseRepository, syncInterval time.Duration) *Scheduler {
+	if syncInterval <= 0 {
+		syncInterval = time.Minute
+	}
+
+	cronLog := cronLogger{}
+	hostname, _ := os.Hostname()
+	return &Scheduler{
+		executor:     executor,
+		workflowRepo: workflowRepo,
+		leaseRepo:    leaseRepo,
+		instanceID:   fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		syncInterval: syncInterval,
+		cron: cron.New(
+			cron.WithParser(scheduleSpecParser{}),
+			cron.WithLogger(cronLog),
+			// A run that is still going when its next tick comes is not doubled up
+			cron.WithChain(cron.Recover(cronLog), cron.SkipIfStillRunning(cronLog)),
+		),
+		workflows: make(map[primitive.ObjectID]*scheduledWorkflow),
+	}
+}
+
+// Start loads the schedules of all active workflows and starts firing them
+func (s *Scheduler) Start(ctx context.Context) error {
+	if err := s.syncAll(ctx); err != nil {
+		return err
+	}
+	logger.Log.Infow("Scheduler started", "workflows", len(s.workflows))
+	s.cron.Start()
+
+	syncCtx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.wg.Add(1)
+	go s.resync(syncCtx)
+	return nil
+}
+
+// Stop stops firing schedules and waits for running executions to finish,
+// or until the context expires
+func (s *Scheduler) Stop(ctx context.Context) {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	s.wg.Wait()
+
+	select {
+	case <-s.cron.Stop().Done():
+		logger.Log.Info("Scheduler stopped")
+	case <-ctx.Done():
+		logger.Log.Warn("Scheduler stopped with executions still running")
+	}
+}
+
+// Sync registers the schedule triggers of a workflow, replacing any previous
+// ones. Inactive workflows are unscheduled.
+func (s *Scheduler) Sync(workflow *domain.Workflow) {
+	specs := make(map[string]string)
+	if workflow.Status == domain.WorkflowStatusActive {
+		specs = scheduleSpecs(workflow)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.workflows[workflow.ID]
+	if ok && sameSpecs(current.specs, specs) {
+		return
+	}
+	if ok {
+		for _, entry := range current.entries {
+			s.cron.Remove(entry)
+		}
+		delete(s.workflows, workflow.ID)
+	}
+	if len(specs) == 0 {
+		return
+	}
+
+	scheduled := &scheduledWorkflow{specs: specs}
+	for nodeID, spec := range specs {
+		entry, err := s.cron.AddJob(spec, &scheduledRun{
+			scheduler:  s,
+			workflowID: workflow.ID,
+			nodeID:     nodeID,
+		})
+		if err != nil {
+			logger.Log.Warnw("Skipping invalid schedule",
+				"workflowId", workflow.ID.Hex(),
+				"nodeId", nodeID,
+				"error", err,
+			)
+			continue
+		}
+		scheduled.entries = append(scheduled.entries, entry)
+	}
+	s.workflows[workflow.ID] = scheduled
+}
+
+// syncAll reconciles the registered schedules with the active workflows
+func (s *Scheduler) syncAll(ctx context.Context) error {
+	status := domain.WorkflowStatusActive
+	workflows, _, err := s.workflowRepo.GetAll(ctx, repository.WorkflowFilter{Status: &status})
+	if err != nil {
+		return err
+	}
+
+	active := make(map[primitive.ObjectID]bool, len(workflows))
+	for i := range workflows {
+		active[workflows[i].ID] = true
+		s.Sync(&workflows[i])
+	}
+
+	// Drop workflows that were deactivated or deleted elsewhere
+	s.mu.Lock()
+	var stale []primitive.ObjectID
+	for id := range s.workflows {
+		if !active[id] {
+			stale = append(stale, id)
+		}
+	}
+	s.mu.Unlock()
+	for _, id := range stale {
+		s.Sync(&domain.Workflow{ID: id})
+	}
+	return nil
+}
+
+func (s *Scheduler) resync(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.syncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.syncAll(ctx); err != nil && ctx.Err() == nil {
+				logger.Log.Errorw("Failed to sync schedules", "error", err)
+			}
+		}
+	}
+}
+
+// scheduledRun executes a workflow from one of its schedule triggers
+type scheduledRun struct {
+	scheduler  *Scheduler
+	workflowID primitive.ObjectID
+	nodeID     string
+}
+
+// Run implements cron.Job
+func (r *scheduledRun) Run() {
+	firedAt := time.Now()
+	// Ticks fall on whole seconds; rounding absorbs clock drift between instances
+	release, ok := r.scheduler.lease(r.workflowID, r.nodeID, firedAt.Round(time.Second))
+	if !ok {
+		return
+	}
+	defer release()
+
+	result, err := r.scheduler.executor.Execute(context.Background(), &ExecuteRequest{
+		WorkflowID:    r.workflowID,
+		TriggerType:   "schedule",
+		TriggerNodeID: r.nodeID,
+		Input: map[string]any{
+			"scheduledAt": firedAt.UTC().Format(time.RFC3339),
+		},
+		Metadata: map[string]any{
+			"triggerNodeId": r.nodeID,
+		},
+	})
+	if err != nil {
+		logger.Log.Errorw("Scheduled execution failed",
+			"workflowId", r.workflowID.Hex(),
+			"nodeId", r.nodeID,
+			"error", err,
+		)
+		return
+	}
+
+	logger.Log.Infow("Scheduled execution finished",
+		"workflowId", r.workflowID.Hex(),
+		"nodeId", r.nodeID,
+		"executionId", result.ExecutionID,
+		"status", result.Status,
+	)
+}
+
+// lease claims a schedule tick and keeps the lease renewed until the returned
+// function is called. It reports false when another instance has the tick.
+func (s *Scheduler) lease(workflowID primitive.ObjectID, nodeID string, scheduledAt time.Time) (func(), bool) {
+	id := workflowID.Hex() + ":" + nodeID
+	ctx := context.Background()
+
+	acquired, err := s.leaseRepo.Acquire(ctx, id, scheduledAt, s.instanceID, scheduleLeaseTTL)
+	if err != nil {
+		logger.Log.Errorw("Failed to take schedule lease", "workflowId", workflowID.Hex(), "nodeId", nodeID, "error", err)
+		return nil, false
+	}
+	if !acquired {
+		logger.Log.Debugw("Schedule tick runs on another instance", "workflowId", workflowID.Hex(), "nodeId", nodeID)
+		return nil, false
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(scheduleLeaseTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := s.leaseRepo.Renew(ctx, id, s.instanceID, scheduleLeaseTTL); err != nil {
+					logger.Log.Warnw("Failed to renew schedule lease", "workflowId", workflowID.Hex(), "nodeId", nodeID, "error", err)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		wg.Wait()
+		if err := s.leaseRepo.Release(ctx, id, s.instanceID); err != nil {
+			logger.Log.Warnw("Failed to release schedule lease", "workflowId", workflowID.Hex(), "nodeId", nodeID, "error", err)
+		}
+	}, true
+}
+
+// scheduleSpecs returns the schedule of every schedule trigger of a workflow.
+// Time zones are folded into the spec so a zone change re-registers the job.
+func scheduleSpecs(workflow *domain.Workflow) map[string]string {
+	specs := make(map[string]string)
+	for _, n := range workflow.Nodes {
+		if n.Type != domain.NodeTypeTrigger || n.Data.TriggerType != "schedule" || n.Data.Schedule == "" {
+			continue
+		}
+		spec := strings.TrimSpace(n.Data.Schedule)
+		inlineZone := strings.HasPrefix(spec, "CRON_TZ=") || strings.HasPrefix(spec, "TZ=")
+		if n.Data.Timezone != "" && !inlineZone {
+			spec = "CRON_TZ=" + n.Data.Timezone + " " + spec
+		}
+		specs[n.ID] = spec
+	}
+	return specs
+}
+
+func sameSpecs(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// scheduleSpecParser makes cron use the same rules as trigger validation
+type scheduleSpecParser struct{}
+
+func (scheduleSpecParser) Parse(spec string) (cron.Schedule, error) {
+	return node.ParseSchedule(spec, "")
+}
+
+// cronLogger forwards cron's logs to the application logger
+type cronLogger struct{}
+
+func (cronLogger) Info(msg string, keysAndValues ...any) {
+	logger.Log.Debugw("cron: "+msg, keysAndValues...)
+}
+
+func (cronLogger) Error(err error, msg string, keysAndValues ...any) {
+	logger.Log.Errorw("cron: "+msg, append(keysAndValues, "error", err)...)
+}
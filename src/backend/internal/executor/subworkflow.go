@@ -0,0 +1,124 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nodetl/nodetl/internal/domain"
+	"github.com/nodetl/nodetl/internal/node"
+	"github.com/nodetl/nodetl/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxWorkflowDepth limits how deeply workflows may call each other, which
+// also stops workflows that (indirectly) call themselves
+const maxWorkflowDepth = 10
+
+// Execution metadata keys linking child executions to their parent
+const (
+	metaParentExecutionID = "parentExecutionId"
+	metaParentWorkflowID  = "parentWorkflowId"
+	metaParentNodeID      = "parentNodeId"
+	metaRootExecutionID   = "rootExecutionId"
+	metaDepth             = "depth"
+)
+
+// workflowRunner lets a node run another workflow as a child of the current
+// execution
+type workflowRunner struct {
+	run    *executionRun
+	nodeID string
+}
+
+// RunWorkflow implements node.WorkflowRunner
+func (w *workflowRunner) RunWorkflow(ctx context.Context, req node.WorkflowRunRequest) (*node.WorkflowRunResult, error) {
+	e := w.run.executor
+	parent := w.run.execution
+
+	depth := metadataInt(parent.Metadata, metaDepth) + 1
+	if depth > maxWorkflowDepth {
+		return nil, fmt.Errorf("maximum workflow depth of %d exceeded", maxWorkflowDepth)
+	}
+
+	var execReq *ExecuteRequest
+	if req.WorkflowID != "" {
+		workflowID, err := primitive.ObjectIDFromHex(req.WorkflowID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid workflow ID: %s", req.WorkflowID)
+		}
+		execReq = &ExecuteRequest{WorkflowID: workflowID, Input: req.Input}
+	} else {
+		endpointReq, _, err := e.EndpointRequest(ctx, req.EndpointPath, req.Input)
+		if err != nil {
+			return nil, err
+		}
+		execReq = endpointReq
+	}
+
+	rootID := parent.ID.Hex()
+	if id, ok := parent.Metadata[metaRootExecutionID].(string); ok && id != "" {
+		rootID = id
+	}
+	execReq.TriggerType = "workflow"
+	execReq.parentEnvironment = parent.Environment
+	execReq.Metadata = map[string]any{
+		metaParentExecutionID: parent.ID.Hex(),
+		metaParentWorkflowID:  parent.WorkflowID.Hex(),
+		metaParentNodeID:      w.nodeID,
+		metaRootExecutionID:   rootID,
+		metaDepth:             depth,
+	}
+
+	workflow, execution, err := e.createExecution(ctx, execReq, domain.ExecutionStatusRunning)
+	if err != nil {
+		return nil, err
+	}
+
+	if !req.Wait {
+		// The child outlives this run, so it must not inherit its cancellation
+		go func() {
+			if _, err := e.runExecution(context.WithoutCancel(ctx), workflow, execution, execReq.TriggerPath, ""); err != nil {
+				logger.Log.Errorw("Child execution failed",
+					"executionId", execution.ID.Hex(),
+					"parentExecutionId", parent.ID.Hex(),
+					"error", err,
+				)
+			}
+		}()
+		return &node.WorkflowRunResult{
+			ExecutionID: execution.ID.Hex(),
+			Status:      string(execution.Status),
+		}, nil
+	}
+
+	result, err := e.runExecution(ctx, workflow, execution, execReq.TriggerPath, "")
+	if err != nil {
+		return nil, err
+	}
+
+	runResult := &node.WorkflowRunResult{
+		ExecutionID: result.ExecutionID,
+		Status:      string(result.Status),
+		Output:      result.Output,
+	}
+	if result.Error != nil {
+		runResult.Error = result.Error.Message
+	}
+	return runResult, nil
+}
+
+// metadataInt reads a number from execution metadata, which may have been
+// decoded from Mongo as any integer or float type
+func metadataInt(metadata map[string]any, key string) int {
+	switch v := metadata[key].(type) {
+	case int:
+		return v
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	}
+	return 0
+}
@@ -0,0 +1,77 @@
+package executor
+
+import (
+	"context"
+	"errors"
+
+	"github.com/nodetl/nodetl/internal/domain"
+	"github.com/nodetl/nodetl/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Execution metadata keys for tracing
+const (
+	metaTraceID     = "traceId"
+	metaTraceparent = "traceparent" // Set when the execution continues its caller's trace
+)
+
+// startExecutionSpan starts the span of a run of an execution, in the trace
+// the execution was created in
+func startExecutionSpan(ctx context.Context, workflow *domain.Workflow, execution *domain.Execution) (context.Context, trace.Span) {
+	traceID, _ := execution.Metadata[metaTraceID].(string)
+	traceparent, _ := execution.Metadata[metaTraceparent].(string)
+
+	return tracing.Tracer().Start(tracing.Resume(ctx, traceID, traceparent), "execution "+workflow.Name,
+		trace.WithAttributes(
+			attribute.String("nodetl.workflow.id", workflow.ID.Hex()),
+			attribute.String("nodetl.workflow.name", workflow.Name),
+			attribute.String("nodetl.execution.id", execution.ID.Hex()),
+			attribute.String("nodetl.trigger.type", execution.TriggerType),
+			attribute.String("nodetl.environment", execution.Environment),
+		),
+	)
+}
+
+// endExecutionSpan records the outcome of an execution on its span
+func endExecutionSpan(span trace.Span, execution *domain.Execution) {
+	span.SetAttributes(attribute.String("nodetl.execution.status", string(execution.Status)))
+
+	var err error
+	if execution.Error != nil && execution.Status != domain.ExecutionStatusCompleted {
+		err = errors.New(execution.Error.Message)
+		span.SetAttributes(attribute.String("nodetl.error.node_id", execution.Error.NodeID))
+	}
+	tracing.End(span, err)
+}
+
+// startNodeSpan starts the span of a node run. Spans of the nodes a node
+// runs itself, such as a loop body, and of the requests it sends are its
+// children.
+func startNodeSpan(ctx context.Context, n *domain.Node) (context.Context, trace.Span) {
+	name := n.Label
+	if name == "" {
+		name = n.Type
+	}
+	return tracing.Tracer().Start(ctx, "node "+name,
+		trace.WithAttributes(
+			attribute.String("nodetl.node.id", n.ID),
+			attribute.String("nodetl.node.type", n.Type),
+			attribute.String("nodetl.node.label", n.Label),
+		),
+	)
+}
+
+// endNodeSpan records the outcome of a node run on its span
+func endNodeSpan(span trace.Span, nodeLog domain.NodeExecutionLog) {
+	span.SetAttributes(attribute.String("nodetl.node.status", string(nodeLog.Status)))
+	if len(nodeLog.Attempts) > 0 {
+		span.SetAttributes(attribute.Int("nodetl.node.attempts", len(nodeLog.Attempts)))
+	}
+
+	var err error
+	if nodeLog.Error != nil {
+		err = errors.New(*nodeLog.Error)
+	}
+	tracing.End(span, err)
+}
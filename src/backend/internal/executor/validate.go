@@ -0,0 +1,212 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nodetl/nodetl/internal/domain"
+	"github.com/nodetl/nodetl/internal/redact"
+)
+
+// Validate statically checks a workflow before it goes live. It reports every
+// issue found instead of stopping at the first one; an empty result means the
+// workflow is valid.
+func (e *FlowExecutor) Validate(ctx context.Context, workflow *domain.Workflow) []domain.ValidationIssue {
+	issues := []domain.ValidationIssue{}
+	graph := e.buildNodeGraph(workflow)
+
+	// Node types and configuration
+	var triggers []string
+	for i := range workflow.Nodes {
+		n := &workflow.Nodes[i]
+		if n.Type == domain.NodeTypeTrigger {
+			triggers = append(triggers, n.ID)
+		}
+
+		executor, ok := e.nodeRegistry.Get(n.Type)
+		if !ok {
+			issues = append(issues, domain.ValidationIssue{
+				NodeID:  n.ID,
+				Code:    domain.ValidationUnknownNodeType,
+				Message: fmt.Sprintf("no executor registered for node type %q", n.Type),
+			})
+			continue
+		}
+		if err := executor.Validate(e.prepareNodeData(ctx, workflow, n)); err != nil {
+			issues = append(issues, domain.ValidationIssue{
+				NodeID:  n.ID,
+				Code:    domain.ValidationInvalidConfig,
+				Message: err.Error(),
+			})
+		}
+	}
+
+	if len(triggers) == 0 {
+		issues = append(issues, domain.ValidationIssue{
+			Code:    domain.ValidationNoTrigger,
+			Message: "workflow has no trigger node",
+		})
+	}
+
+	// Edges must connect existing nodes through existing ports
+	for _, edge := range workflow.Edges {
+		source, sourceOK := graph.Nodes[edge.Source]
+		target, targetOK := graph.Nodes[edge.Target]
+		if !sourceOK || !targetOK {
+			missing := edge.Source
+			nodeID := edge.Target
+			if sourceOK {
+				missing, nodeID = edge.Target, edge.Source
+			}
+			issues = append(issues, domain.ValidationIssue{
+				NodeID:  nodeID,
+				EdgeID:  edge.ID,
+				Code:    domain.ValidationMissingNode,
+				Message: fmt.Sprintf("edge references missing node %q", missing),
+			})
+			continue
+		}
+		if edge.SourceHandle != "" && !hasOutputPort(source, edge.SourceHandle) {
+			issues = append(issues, domain.ValidationIssue{
+				NodeID:  source.ID,
+				EdgeID:  edge.ID,
+				Code:    domain.ValidationMissingPort,
+				Message: fmt.Sprintf("node has no output port %q", edge.SourceHandle),
+			})
+		}
+		if edge.TargetHandle != "" && !hasInputPort(target, edge.TargetHandle) {
+			issues = append(issues, domain.ValidationIssue{
+				NodeID:  target.ID,
+				EdgeID:  edge.ID,
+				Code:    domain.ValidationMissingPort,
+				Message: fmt.Sprintf("node has no input port %q", edge.TargetHandle),
+			})
+		}
+	}
+
+	// Cycles are only allowed when they lead back into a loop node, which is
+	// how loop bodies are wired
+	reachable := make(map[string]bool)
+	reported := make(map[domain.Edge]bool)
+	for _, triggerID := range triggers {
+		for id := range graph.ReachableFrom(triggerID) {
+			reachable[id] = true
+		}
+		for edge := range graph.BackEdges(triggerID) {
+			if reported[edge] || graph.Nodes[edge.Target].Type == domain.NodeTypeLoop {
+				continue
+			}
+			reported[edge] = true
+			issues = append(issues, domain.ValidationIssue{
+				NodeID:  edge.Source,
+				EdgeID:  edge.ID,
+				Code:    domain.ValidationCycle,
+				Message: fmt.Sprintf("edge to %q creates a cycle", edge.Target),
+			})
+		}
+	}
+
+	if len(triggers) > 0 {
+		for _, n := range workflow.Nodes {
+			if !reachable[n.ID] {
+				issues = append(issues, domain.ValidationIssue{
+					NodeID:  n.ID,
+					Code:    domain.ValidationUnreachable,
+					Message: "node cannot be reached from any trigger",
+				})
+			}
+		}
+	}
+
+	if workflow.Settings != nil {
+		if err := redact.ValidatePaths(workflow.Settings.RedactPaths); err != nil {
+			issues = append(issues, domain.ValidationIssue{
+				Code:    domain.ValidationInvalidConfig,
+				Message: fmt.Sprintf("invalid redactPaths: %v", err),
+			})
+		}
+	}
+
+	// Triggers and the endpoint may only be bound to environments the
+	// project defines
+	project, _ := e.workflowProject(ctx, workflow)
+	hasEnvironment := func(name string) bool {
+		return project != nil && project.Environment(name) != nil
+	}
+	for _, n := range workflow.Nodes {
+		if n.Type == domain.NodeTypeTrigger && n.Data.Environment != "" && !hasEnvironment(n.Data.Environment) {
+			issues = append(issues, domain.ValidationIssue{
+				NodeID:  n.ID,
+				Code:    domain.ValidationUnknownEnvironment,
+				Message: fmt.Sprintf("environment %q is not defined in the workflow's project", n.Data.Environment),
+			})
+		}
+	}
+	if workflow.Endpoint != nil && workflow.Endpoint.Environment != "" && !hasEnvironment(workflow.Endpoint.Environment) {
+		issues = append(issues, domain.ValidationIssue{
+			Code:    domain.ValidationUnknownEnvironment,
+			Message: fmt.Sprintf("endpoint environment %q is not defined in the workflow's project", workflow.Endpoint.Environment),
+		})
+	}
+
+	return issues
+}
+
+// hasOutputPort checks a source handle against the ports a node can emit on
+func hasOutputPort(n *domain.Node, port string) bool {
+	// Every node can route failures through its error port
+	if port == errorPort {
+		return true
+	}
+	for _, p := range n.Outputs {
+		if p.ID == port || p.Name == port {
+			return true
+		}
+	}
+	for _, c := range n.Data.Conditions {
+		if c.OutputID == port {
+			return true
+		}
+	}
+
+	builtIn := builtInNodeType(n.Type)
+	if builtIn == nil {
+		// Custom node types declare their ports on the node only
+		return len(n.Outputs) == 0
+	}
+	for _, p := range builtIn.Outputs {
+		if p.Name == port {
+			return true
+		}
+	}
+	return false
+}
+
+// hasInputPort checks a target handle against the ports a node accepts
+func hasInputPort(n *domain.Node, port string) bool {
+	for _, p := range n.Inputs {
+		if p.ID == port || p.Name == port {
+			return true
+		}
+	}
+
+	builtIn := builtInNodeType(n.Type)
+	if builtIn == nil {
+		return len(n.Inputs) == 0
+	}
+	for _, p := range builtIn.Inputs {
+		if p.Name == port {
+			return true
+		}
+	}
+	return false
+}
+
+func builtInNodeType(nodeType string) *domain.NodeType {
+	for _, t := range domain.GetBuiltInNodeTypes() {
+		if t.Type == nodeType {
+			return &t
+		}
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/nodetl/nodetl/internal/domain"
+	"github.com/nodetl/nodetl/internal/repository"
+	"github.com/nodetl/nodetl/pkg/logger"
+)
+
+// WorkerPool runs queued executions in the background with a bounded number
+// of workers. Jobs are stored in Mongo, so any instance can pick them up.
+type WorkerPool struct {
+	executor     *FlowExecutor
+	jobRepo      repository.ExecutionJobRepository
+	workers      int
+	pollInterval time.Duration
+	workerPrefix string
+
+	wake   chan struct{}
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewWorkerPool creates a new worker pool
+func NewWorkerPool(executor *FlowExecutor, jobRepo repository.ExecutionJobRepository, workers int, pollInterval time.Duration) *WorkerPool {
+	if workers <= 0 {
+		workers = 1
+	}
+	if pollInterval <= 0 {
+		pollInterval = time.Second
+	}
+
+	hostname, _ := os.Hostname()
+	return &WorkerPool{
+		executor:     executor,
+		jobRepo:      jobRepo,
+		workers:      workers,
+		pollInterval: pollInterval,
+		workerPrefix: fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+// Submit creates a pending execution and queues it for a worker. It returns
+// immediately with the execution ID so the caller can poll for the result.
+func (p *WorkerPool) Submit(ctx context.Context, req *ExecuteRequest) (*ExecuteResult, error) {
+	workflow, execution, err := p.executor.createExecution(ctx, req, domain.ExecutionStatusPending)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &domain.ExecutionJob{
+		ExecutionID: execution.ID,
+		WorkflowID:  execution.WorkflowID,
+		TriggerPath: req.TriggerPath,
+	}
+	if err := p.jobRepo.Enqueue(ctx, job); err != nil {
+		err = fmt.Errorf("failed to enqueue execution: %w", err)
+		p.failUnqueued(context.WithoutCancel(ctx), workflow, execution, err)
+		return nil, err
+	}
+
+	// Wake an idle worker without waiting for the next poll
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+
+	return &ExecuteResult{
+		ExecutionID: execution.ID.Hex(),
+		Status:      execution.Status,
+	}, nil
+}
+
+// failUnqueued marks an execution whose job could not be queued as failed,
+// since no worker would ever pick it up
+func (p *WorkerPool) failUnqueued(ctx context.Context, workflow *domain.Workflow, execution *domain.Execution, err error) {
+	now := time.Now()
+	execution.Status = domain.ExecutionStatusFailed
+	execution.Error = &domain.ExecutionError{Message: err.Error()}
+	execution.CompletedAt = &now
+	p.executor.publishFinished(p.executor.saveExecution(ctx, execution, p.executor.redactionRules(ctx, workflow)))
+}
+
+// Start launches the workers
+func (p *WorkerPool) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+
+	for i := 0; i < p.workers; i++ {
+		p.wg.Add(1)
+		go p.work(ctx, fmt.Sprintf("%s-%d", p.workerPrefix, i))
+	}
+
+	logger.Log.Infow("Execution worker pool started", "workers", p.workers)
+}
+
+// Stop stops claiming new jobs and waits for running executions to finish,
+// or until the context expires
+func (p *WorkerPool) Stop(ctx context.Context) {
+	if p.cancel == nil {
+		return
+	}
+	p.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		logger.Log.Info("Execution worker pool stopped")
+	case <-ctx.Done():
+		logger.Log.Warn("Execution worker pool stopped with executions still running")
+	}
+}
+
+func (p *WorkerPool) work(ctx context.Context, workerID string) {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Drain the queue before going back to sleep
+		for ctx.Err() == nil && p.runNext(ctx, workerID) {
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// runNext claims and runs one job. It reports whether a job was found.
+func (p *WorkerPool) runNext(ctx context.Context, workerID string) bool {
+	job, err := p.jobRepo.ClaimNext(ctx, workerID)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Log.Errorw("Failed to claim execution job", "worker", workerID, "error", err)
+		}
+		return false
+	}
+	if job == nil {
+		return false
+	}
+
+	// Executions are not tied to the pool context so a shutdown lets them finish
+	runCtx := context.Background()
+	if _, err := p.executor.RunPending(runCtx, job.ExecutionID, job.TriggerPath); err != nil {
+		logger.Log.Errorw("Queued execution failed",
+			"worker", workerID,
+			"executionId", job.ExecutionID.Hex(),
+			"error", err,
+		)
+	}
+
+	if err := p.jobRepo.Delete(runCtx, job.ID); err != nil {
+		logger.Log.Errorw("Failed to delete execution job", "jobId", job.ID.Hex(), "error", err)
+	}
+	return true
+}
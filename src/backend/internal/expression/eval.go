@@ -0,0 +1,554 @@
+package expression
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// projection is the intermediate result of a wildcard or filter. Field
+// access on it applies to every element, e.g. items[*].sku.
+type projection []any
+
+type evaluator struct {
+	scope *Scope
+}
+
+func (ev *evaluator) eval(n node) (any, error) {
+	switch n := n.(type) {
+	case *literalNode:
+		return n.value, nil
+
+	case *identNode:
+		return ev.lookup(n.name)
+
+	case *arrayNode:
+		items := make([]any, len(n.items))
+		for i, item := range n.items {
+			v, err := ev.eval(item)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = settle(v)
+		}
+		return items, nil
+
+	case *memberNode:
+		target, err := ev.eval(n.target)
+		if err != nil {
+			return nil, err
+		}
+		return project(target, func(v any) (any, error) {
+			return field(v, n.name), nil
+		})
+
+	case *indexNode:
+		target, err := ev.eval(n.target)
+		if err != nil {
+			return nil, err
+		}
+		index, err := ev.eval(n.index)
+		if err != nil {
+			return nil, err
+		}
+		index = settle(index)
+		// A position picks from the selection itself, e.g. items[?(@.ok)][0]
+		if p, ok := target.(projection); ok {
+			if _, isKey := index.(string); !isKey {
+				return element([]any(p), index), nil
+			}
+		}
+		return project(target, func(v any) (any, error) {
+			return element(v, index), nil
+		})
+
+	case *wildcardNode:
+		target, err := ev.eval(n.target)
+		if err != nil {
+			return nil, err
+		}
+		out := projection{}
+		each(target, func(v any) {
+			out = append(out, children(v)...)
+		})
+		return out, nil
+
+	case *filterNode:
+		target, err := ev.eval(n.target)
+		if err != nil {
+			return nil, err
+		}
+		out := projection{}
+		var evalErr error
+		each(target, func(v any) {
+			for _, item := range children(v) {
+				if evalErr != nil {
+					return
+				}
+				keep, err := ev.withCurrent(item).eval(n.predicate)
+				if err != nil {
+					evalErr = err
+					return
+				}
+				if Truthy(settle(keep)) {
+					out = append(out, item)
+				}
+			}
+		})
+		if evalErr != nil {
+			return nil, evalErr
+		}
+		return out, nil
+
+	case *callNode:
+		args := make([]any, len(n.args))
+		for i, arg := range n.args {
+			v, err := ev.eval(arg)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = settle(v)
+		}
+		result, err := n.fn.call(args)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", n.name, err)
+		}
+		return result, nil
+
+	case *unaryNode:
+		v, err := ev.eval(n.operand)
+		if err != nil {
+			return nil, err
+		}
+		v = settle(v)
+		if n.op == "!" {
+			return !Truthy(v), nil
+		}
+		num, ok := toNumber(v)
+		if !ok {
+			return nil, fmt.Errorf("cannot negate %s", typeName(v))
+		}
+		return -num, nil
+
+	case *binaryNode:
+		return ev.binary(n)
+
+	case *conditionalNode:
+		cond, err := ev.eval(n.cond)
+		if err != nil {
+			return nil, err
+		}
+		if Truthy(settle(cond)) {
+			return ev.eval(n.then)
+		}
+		return ev.eval(n.otherwise)
+	}
+	return nil, fmt.Errorf("unsupported expression")
+}
+
+// withCurrent returns an evaluator for a filter predicate, where @ is the
+// element being tested and bare names are its fields
+func (ev *evaluator) withCurrent(item any) *evaluator {
+	scope := ev.scope.WithRoot("@", item)
+	if m, ok := item.(map[string]any); ok {
+		scope.Data = m
+	}
+	return &evaluator{scope: scope}
+}
+
+func (ev *evaluator) lookup(name string) (any, error) {
+	if strings.HasPrefix(name, "$") || name == "@" {
+		value, ok := ev.scope.Roots[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown variable %s", name)
+		}
+		return value, nil
+	}
+	return ev.scope.Data[name], nil
+}
+
+func (ev *evaluator) binary(n *binaryNode) (any, error) {
+	left, err := ev.eval(n.left)
+	if err != nil {
+		return nil, err
+	}
+	left = settle(left)
+
+	// Short-circuiting operators
+	switch n.op {
+	case "&&":
+		if !Truthy(left) {
+			return false, nil
+		}
+		right, err := ev.eval(n.right)
+		if err != nil {
+			return nil, err
+		}
+		return Truthy(settle(right)), nil
+	case "||":
+		if Truthy(left) {
+			return true, nil
+		}
+		right, err := ev.eval(n.right)
+		if err != nil {
+			return nil, err
+		}
+		return Truthy(settle(right)), nil
+	case "??":
+		if left != nil {
+			return left, nil
+		}
+		right, err := ev.eval(n.right)
+		if err != nil {
+			return nil, err
+		}
+		return settle(right), nil
+	}
+
+	right, err := ev.eval(n.right)
+	if err != nil {
+		return nil, err
+	}
+	right = settle(right)
+
+	switch n.op {
+	case "==":
+		return Equal(left, right), nil
+	case "!=":
+		return !Equal(left, right), nil
+	case "<", "<=", ">", ">=":
+		cmp, ok := Compare(left, right)
+		if !ok {
+			return false, nil
+		}
+		switch n.op {
+		case "<":
+			return cmp < 0, nil
+		case "<=":
+			return cmp <= 0, nil
+		case ">":
+			return cmp > 0, nil
+		default:
+			return cmp >= 0, nil
+		}
+	case "+":
+		// Strings concatenate with anything
+		if ls, ok := left.(string); ok {
+			return ls + Stringify(right), nil
+		}
+		if rs, ok := right.(string); ok {
+			return Stringify(left) + rs, nil
+		}
+	}
+
+	l, lok := toNumber(left)
+	r, rok := toNumber(right)
+	if !lok || !rok {
+		return nil, fmt.Errorf("cannot apply %s to %s and %s", n.op, typeName(left), typeName(right))
+	}
+	switch n.op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/":
+		if r == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return l / r, nil
+	case "%":
+		if r == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return math.Mod(l, r), nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", n.op)
+}
+
+// project applies an accessor to a value, or to every element of a projection
+func project(target any, access func(any) (any, error)) (any, error) {
+	p, ok := target.(projection)
+	if !ok {
+		return access(target)
+	}
+	out := projection{}
+	for _, item := range p {
+		v, err := access(item)
+		if err != nil {
+			return nil, err
+		}
+		switch v := v.(type) {
+		case nil:
+		case projection:
+			out = append(out, v...)
+		default:
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
+
+// each calls fn for the value, or for every element of a projection
+func each(target any, fn func(any)) {
+	if p, ok := target.(projection); ok {
+		for _, item := range p {
+			fn(item)
+		}
+		return
+	}
+	fn(target)
+}
+
+// settle turns a projection into a plain array once it is used as a value
+func settle(v any) any {
+	if p, ok := v.(projection); ok {
+		return []any(p)
+	}
+	return v
+}
+
+// field reads a named field of an object
+func field(v any, name string) any {
+	switch m := v.(type) {
+	case map[string]any:
+		return m[name]
+	case primitive.M:
+		return m[name]
+	case primitive.D:
+		for _, e := range m {
+			if e.Key == name {
+				return e.Value
+			}
+		}
+	case map[string]string:
+		if s, ok := m[name]; ok {
+			return s
+		}
+	}
+	return nil
+}
+
+// element reads an array element by position (negative counts from the end)
+// or an object field by name
+func element(v any, index any) any {
+	if key, ok := index.(string); ok {
+		return field(v, key)
+	}
+	i, ok := toNumber(index)
+	if !ok {
+		return nil
+	}
+	items, ok := toSlice(v)
+	if !ok {
+		return nil
+	}
+	pos := int(i)
+	if pos < 0 {
+		pos += len(items)
+	}
+	if pos < 0 || pos >= len(items) {
+		return nil
+	}
+	return items[pos]
+}
+
+// children returns the elements of an array or the values of an object,
+// ordered by key
+func children(v any) []any {
+	if items, ok := toSlice(v); ok {
+		return items
+	}
+	if m, ok := v.(map[string]any); ok {
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		out := make([]any, len(keys))
+		for i, k := range keys {
+			out[i] = m[k]
+		}
+		return out
+	}
+	return nil
+}
+
+// toSlice converts arrays of any element type to []any
+func toSlice(v any) ([]any, bool) {
+	switch s := v.(type) {
+	case []any:
+		return s, true
+	case projection:
+		return s, true
+	case primitive.A:
+		return s, true
+	case []map[string]any:
+		out := make([]any, len(s))
+		for i, item := range s {
+			out[i] = item
+		}
+		return out, true
+	case []string:
+		out := make([]any, len(s))
+		for i, item := range s {
+			out[i] = item
+		}
+		return out, true
+	case nil:
+		return nil, false
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+	out := make([]any, rv.Len())
+	for i := range out {
+		out[i] = rv.Index(i).Interface()
+	}
+	return out, true
+}
+
+// toNumber converts numeric values to float64
+func toNumber(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// Equal compares two values. Numbers compare by value whatever their type,
+// and numbers equal strings holding the same number.
+func Equal(a, b any) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	an, aok := toNumber(a)
+	bn, bok := toNumber(b)
+	if aok && bok {
+		return an == bn
+	}
+	if at, ok := a.(time.Time); ok {
+		if bt, ok := toTime(b); ok {
+			return at.Equal(bt)
+		}
+	}
+	if bt, ok := b.(time.Time); ok {
+		if at, ok := toTime(a); ok {
+			return at.Equal(bt)
+		}
+	}
+	as, aIsString := a.(string)
+	bs, bIsString := b.(string)
+	if aIsString && bIsString {
+		return as == bs
+	}
+	if (aIsString && bok) || (bIsString && aok) {
+		return Stringify(a) == Stringify(b)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// Compare orders two numbers, dates or strings. Numeric strings compare as
+// numbers with numbers. It reports false if the values cannot be ordered.
+func Compare(a, b any) (int, bool) {
+	an, aok := toNumber(a)
+	bn, bok := toNumber(b)
+	if aok && !bok {
+		if s, ok := b.(string); ok {
+			bn, bok = parseNumber(s)
+		}
+	}
+	if bok && !aok {
+		if s, ok := a.(string); ok {
+			an, aok = parseNumber(s)
+		}
+	}
+	if aok && bok {
+		return compareOrdered(an, bn), true
+	}
+
+	_, aIsTime := a.(time.Time)
+	_, bIsTime := b.(time.Time)
+	if aIsTime || bIsTime {
+		at, aok := toTime(a)
+		bt, bok := toTime(b)
+		if !aok || !bok {
+			return 0, false
+		}
+		return at.Compare(bt), true
+	}
+
+	as, aok := a.(string)
+	bs, bok := b.(string)
+	if aok && bok {
+		return strings.Compare(as, bs), true
+	}
+	return 0, false
+}
+
+func compareOrdered(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func parseNumber(s string) (float64, bool) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return f, err == nil
+}
+
+func typeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case time.Time:
+		return "date"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	if _, ok := toNumber(v); ok {
+		return "number"
+	}
+	return fmt.Sprintf("%T", v)
+}
@@ -0,0 +1,131 @@
+// Package expression implements the expression language used by nodes to
+// read and compute values, both as bare expressions and inside {{ }}
+// templates.
+//
+// Expressions support:
+//   - scoped roots such as $input, $trigger, $vars, $node["id"].output and
+//     $execution; bare names are looked up in the scope's data
+//   - field access (a.b), indexing (a[0], a[-1], a["key"]), wildcards (a[*].b)
+//     and filters (a[?(@.price > 10)])
+//   - arithmetic (+ - * / %), comparisons, && || ! (or and, or, not), the
+//     conditional a ? b : c and the fallback a ?? b
+//   - function calls, e.g. upper(name), which can also be piped: name | upper
+package expression
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Scope holds the values an expression can refer to
+type Scope struct {
+	// Roots are the variables starting with $, keyed with the $
+	Roots map[string]any
+	// Data resolves bare names such as user.name
+	Data map[string]any
+}
+
+// WithData returns a copy of the scope whose bare names resolve against data
+func (s *Scope) WithData(data map[string]any) *Scope {
+	if s == nil {
+		return &Scope{Data: data}
+	}
+	return &Scope{Roots: s.Roots, Data: data}
+}
+
+// WithRoot returns a copy of the scope with an additional root variable
+func (s *Scope) WithRoot(name string, value any) *Scope {
+	scope := &Scope{Roots: make(map[string]any)}
+	if s != nil {
+		scope.Data = s.Data
+		for k, v := range s.Roots {
+			scope.Roots[k] = v
+		}
+	}
+	scope.Roots[name] = value
+	return scope
+}
+
+// Expression is a parsed expression that can be evaluated repeatedly
+type Expression struct {
+	src  string
+	root node
+}
+
+// maxCached bounds the number of parsed expressions kept in memory
+const maxCached = 10000
+
+var (
+	cache     sync.Map // source -> *Expression
+	cacheSize int
+	cacheMu   sync.Mutex
+)
+
+// Compile parses an expression. Parsed expressions are cached.
+func Compile(src string) (*Expression, error) {
+	if cached, ok := cache.Load(src); ok {
+		return cached.(*Expression), nil
+	}
+
+	root, err := parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", src, err)
+	}
+	expr := &Expression{src: src, root: root}
+
+	cacheMu.Lock()
+	if cacheSize < maxCached {
+		if _, loaded := cache.LoadOrStore(src, expr); !loaded {
+			cacheSize++
+		}
+	}
+	cacheMu.Unlock()
+	return expr, nil
+}
+
+// Eval evaluates the expression in the given scope
+func (e *Expression) Eval(scope *Scope) (any, error) {
+	if scope == nil {
+		scope = &Scope{}
+	}
+	value, err := (&evaluator{scope: scope}).eval(e.root)
+	if err != nil {
+		return nil, fmt.Errorf("evaluating %q: %w", e.src, err)
+	}
+	return settle(value), nil
+}
+
+// String returns the source of the expression
+func (e *Expression) String() string {
+	return e.src
+}
+
+// Eval parses and evaluates an expression
+func Eval(src string, scope *Scope) (any, error) {
+	expr, err := Compile(src)
+	if err != nil {
+		return nil, err
+	}
+	return expr.Eval(scope)
+}
+
+// Truthy reports whether a value counts as true in conditions: false, nil,
+// zero, empty strings and empty collections are false
+func Truthy(v any) bool {
+	switch val := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return val
+	case string:
+		return val != ""
+	case []any:
+		return len(val) > 0
+	case map[string]any:
+		return len(val) > 0
+	}
+	if n, ok := toNumber(v); ok {
+		return n != 0
+	}
+	return true
+}
@@ -0,0 +1,551 @@
+package expression
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// function is a built-in callable from expressions
+type function struct {
+	minArgs int
+	maxArgs int // -1 for any number of arguments
+	call    func(args []any) (any, error)
+}
+
+func (f function) arity() string {
+	switch {
+	case f.maxArgs < 0:
+		return fmt.Sprintf("at least %d arguments", f.minArgs)
+	case f.minArgs == f.maxArgs && f.minArgs == 1:
+		return "1 argument"
+	case f.minArgs == f.maxArgs:
+		return fmt.Sprintf("%d arguments", f.minArgs)
+	}
+	return fmt.Sprintf("%d to %d arguments", f.minArgs, f.maxArgs)
+}
+
+// functions is the table of built-in functions, filled in init so that
+// aliases can share entries
+var functions map[string]function
+
+func init() {
+	functions = map[string]function{
+		// Strings
+		"upper": {1, 1, func(a []any) (any, error) { return strings.ToUpper(Stringify(a[0])), nil }},
+		"lower": {1, 1, func(a []any) (any, error) { return strings.ToLower(Stringify(a[0])), nil }},
+		"trim":  {1, 1, func(a []any) (any, error) { return strings.TrimSpace(Stringify(a[0])), nil }},
+		"len":   {1, 1, fnLength},
+		"substring": {2, 3, func(a []any) (any, error) {
+			runes := []rune(Stringify(a[0]))
+			start := clampIndex(intArg(a[1]), len(runes))
+			end := len(runes)
+			if len(a) > 2 {
+				end = clampIndex(intArg(a[2]), len(runes))
+			}
+			if end < start {
+				return "", nil
+			}
+			return string(runes[start:end]), nil
+		}},
+		"replace": {3, 3, func(a []any) (any, error) {
+			return strings.ReplaceAll(Stringify(a[0]), Stringify(a[1]), Stringify(a[2])), nil
+		}},
+		"split": {2, 2, func(a []any) (any, error) {
+			parts := strings.Split(Stringify(a[0]), Stringify(a[1]))
+			out := make([]any, len(parts))
+			for i, p := range parts {
+				out[i] = p
+			}
+			return out, nil
+		}},
+		"join": {1, 2, func(a []any) (any, error) {
+			items, _ := toSlice(a[0])
+			sep := ","
+			if len(a) > 1 {
+				sep = Stringify(a[1])
+			}
+			parts := make([]string, len(items))
+			for i, item := range items {
+				parts[i] = Stringify(item)
+			}
+			return strings.Join(parts, sep), nil
+		}},
+		"contains": {2, 2, func(a []any) (any, error) {
+			if items, ok := toSlice(a[0]); ok {
+				for _, item := range items {
+					if Equal(item, a[1]) {
+						return true, nil
+					}
+				}
+				return false, nil
+			}
+			if m, ok := a[0].(map[string]any); ok {
+				_, found := m[Stringify(a[1])]
+				return found, nil
+			}
+			return strings.Contains(Stringify(a[0]), Stringify(a[1])), nil
+		}},
+		"startsWith": {2, 2, func(a []any) (any, error) {
+			return strings.HasPrefix(Stringify(a[0]), Stringify(a[1])), nil
+		}},
+		"endsWith": {2, 2, func(a []any) (any, error) {
+			return strings.HasSuffix(Stringify(a[0]), Stringify(a[1])), nil
+		}},
+		"concat": {0, -1, func(a []any) (any, error) {
+			var b strings.Builder
+			for _, v := range a {
+				b.WriteString(Stringify(v))
+			}
+			return b.String(), nil
+		}},
+		"matches": {2, 2, func(a []any) (any, error) {
+			re, err := regexp.Compile(Stringify(a[1]))
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern: %w", err)
+			}
+			return re.MatchString(Stringify(a[0])), nil
+		}},
+		"urlEncode": {1, 1, func(a []any) (any, error) { return url.QueryEscape(Stringify(a[0])), nil }},
+
+		// Conversions
+		"toString": {1, 1, func(a []any) (any, error) { return Stringify(a[0]), nil }},
+		"toNumber": {1, 1, func(a []any) (any, error) {
+			if n, ok := toNumber(a[0]); ok {
+				return n, nil
+			}
+			switch v := a[0].(type) {
+			case string:
+				if n, ok := parseNumber(v); ok {
+					return n, nil
+				}
+				return nil, fmt.Errorf("%q is not a number", v)
+			case bool:
+				if v {
+					return 1.0, nil
+				}
+				return 0.0, nil
+			case nil:
+				return nil, nil
+			}
+			return nil, fmt.Errorf("cannot convert %s to a number", typeName(a[0]))
+		}},
+		"toBoolean": {1, 1, func(a []any) (any, error) {
+			if s, ok := a[0].(string); ok {
+				if b, err := strconv.ParseBool(s); err == nil {
+					return b, nil
+				}
+			}
+			return Truthy(a[0]), nil
+		}},
+		"default": {2, 2, func(a []any) (any, error) {
+			if a[0] == nil || a[0] == "" {
+				return a[1], nil
+			}
+			return a[0], nil
+		}},
+		"json": {1, 1, func(a []any) (any, error) {
+			b, err := json.Marshal(a[0])
+			if err != nil {
+				return nil, err
+			}
+			return string(b), nil
+		}},
+		"parseJson": {1, 1, func(a []any) (any, error) {
+			var v any
+			if err := json.Unmarshal([]byte(Stringify(a[0])), &v); err != nil {
+				return nil, fmt.Errorf("invalid JSON: %w", err)
+			}
+			return v, nil
+		}},
+		"type": {1, 1, func(a []any) (any, error) { return typeName(a[0]), nil }},
+
+		// Arrays and objects
+		"first": {1, 1, func(a []any) (any, error) { return element(a[0], 0.0), nil }},
+		"last":  {1, 1, func(a []any) (any, error) { return element(a[0], -1.0), nil }},
+		"sum": {1, 1, func(a []any) (any, error) {
+			total, _, err := numbers(a[0])
+			return total, err
+		}},
+		"avg": {1, 1, func(a []any) (any, error) {
+			total, count, err := numbers(a[0])
+			if err != nil || count == 0 {
+				return nil, err
+			}
+			return total / float64(count), nil
+		}},
+		"min": {1, -1, func(a []any) (any, error) { return extreme(a, -1) }},
+		"max": {1, -1, func(a []any) (any, error) { return extreme(a, 1) }},
+		"keys": {1, 1, func(a []any) (any, error) {
+			m, ok := a[0].(map[string]any)
+			if !ok {
+				return []any{}, nil
+			}
+			keys := make([]string, 0, len(m))
+			for k := range m {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			out := make([]any, len(keys))
+			for i, k := range keys {
+				out[i] = k
+			}
+			return out, nil
+		}},
+		"values": {1, 1, func(a []any) (any, error) {
+			if _, ok := a[0].(map[string]any); !ok {
+				return []any{}, nil
+			}
+			return children(a[0]), nil
+		}},
+		"pluck": {2, 2, func(a []any) (any, error) {
+			items, _ := toSlice(a[0])
+			name := Stringify(a[1])
+			out := make([]any, 0, len(items))
+			for _, item := range items {
+				out = append(out, field(item, name))
+			}
+			return out, nil
+		}},
+		"unique": {1, 1, func(a []any) (any, error) {
+			items, _ := toSlice(a[0])
+			out := make([]any, 0, len(items))
+		next:
+			for _, item := range items {
+				for _, seen := range out {
+					if Equal(seen, item) {
+						continue next
+					}
+				}
+				out = append(out, item)
+			}
+			return out, nil
+		}},
+		"reverse": {1, 1, func(a []any) (any, error) {
+			if s, ok := a[0].(string); ok {
+				runes := []rune(s)
+				for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+					runes[i], runes[j] = runes[j], runes[i]
+				}
+				return string(runes), nil
+			}
+			items, _ := toSlice(a[0])
+			out := make([]any, len(items))
+			for i, item := range items {
+				out[len(items)-1-i] = item
+			}
+			return out, nil
+		}},
+		"sort": {1, 2, func(a []any) (any, error) {
+			items, _ := toSlice(a[0])
+			out := append([]any(nil), items...)
+			key := ""
+			if len(a) > 1 {
+				key = Stringify(a[1])
+			}
+			sort.SliceStable(out, func(i, j int) bool {
+				x, y := out[i], out[j]
+				if key != "" {
+					x, y = field(x, key), field(y, key)
+				}
+				cmp, _ := Compare(x, y)
+				return cmp < 0
+			})
+			return out, nil
+		}},
+
+		// Math
+		"round": {1, 2, func(a []any) (any, error) {
+			n, err := numberArg(a[0])
+			if err != nil {
+				return nil, err
+			}
+			scale := 1.0
+			if len(a) > 1 {
+				scale = math.Pow(10, float64(intArg(a[1])))
+			}
+			return math.Round(n*scale) / scale, nil
+		}},
+		"floor": {1, 1, mathFn(math.Floor)},
+		"ceil":  {1, 1, mathFn(math.Ceil)},
+		"abs":   {1, 1, mathFn(math.Abs)},
+
+		// Dates
+		"now": {0, 0, func(a []any) (any, error) { return time.Now().UTC(), nil }},
+		"toDate": {1, 1, func(a []any) (any, error) {
+			t, ok := toTime(a[0])
+			if !ok {
+				return nil, fmt.Errorf("cannot convert %s to a date", Stringify(a[0]))
+			}
+			return t, nil
+		}},
+		"formatDate": {2, 2, func(a []any) (any, error) {
+			t, ok := toTime(a[0])
+			if !ok {
+				return nil, fmt.Errorf("cannot convert %s to a date", Stringify(a[0]))
+			}
+			return t.Format(dateLayout(Stringify(a[1]))), nil
+		}},
+		"dateAdd": {2, 2, func(a []any) (any, error) {
+			t, ok := toTime(a[0])
+			if !ok {
+				return nil, fmt.Errorf("cannot convert %s to a date", Stringify(a[0]))
+			}
+			d, err := parseDuration(Stringify(a[1]))
+			if err != nil {
+				return nil, err
+			}
+			return t.Add(d), nil
+		}},
+		"dateDiff": {2, 3, func(a []any) (any, error) {
+			from, ok := toTime(a[0])
+			if !ok {
+				return nil, fmt.Errorf("cannot convert %s to a date", Stringify(a[0]))
+			}
+			to, ok := toTime(a[1])
+			if !ok {
+				return nil, fmt.Errorf("cannot convert %s to a date", Stringify(a[1]))
+			}
+			unit := "ms"
+			if len(a) > 2 {
+				unit = Stringify(a[2])
+			}
+			diff := to.Sub(from)
+			switch unit {
+			case "ms":
+				return float64(diff.Milliseconds()), nil
+			case "s":
+				return diff.Seconds(), nil
+			case "m":
+				return diff.Minutes(), nil
+			case "h":
+				return diff.Hours(), nil
+			case "d":
+				return diff.Hours() / 24, nil
+			}
+			return nil, fmt.Errorf("unknown unit %q, expected ms, s, m, h or d", unit)
+		}},
+	}
+
+	// Aliases
+	functions["length"] = functions["len"]
+	functions["count"] = functions["len"]
+	functions["string"] = functions["toString"]
+	functions["number"] = functions["toNumber"]
+	functions["boolean"] = functions["toBoolean"]
+}
+
+func fnLength(a []any) (any, error) {
+	switch v := a[0].(type) {
+	case nil:
+		return 0.0, nil
+	case string:
+		return float64(len([]rune(v))), nil
+	case map[string]any:
+		return float64(len(v)), nil
+	}
+	if items, ok := toSlice(a[0]); ok {
+		return float64(len(items)), nil
+	}
+	return nil, fmt.Errorf("cannot take the length of %s", typeName(a[0]))
+}
+
+func mathFn(fn func(float64) float64) func([]any) (any, error) {
+	return func(a []any) (any, error) {
+		n, err := numberArg(a[0])
+		if err != nil {
+			return nil, err
+		}
+		return fn(n), nil
+	}
+}
+
+// numberArg accepts numbers and numeric strings
+func numberArg(v any) (float64, error) {
+	if n, ok := toNumber(v); ok {
+		return n, nil
+	}
+	if s, ok := v.(string); ok {
+		if n, ok := parseNumber(s); ok {
+			return n, nil
+		}
+	}
+	return 0, fmt.Errorf("expected a number, got %s", typeName(v))
+}
+
+func intArg(v any) int {
+	n, _ := numberArg(v)
+	return int(n)
+}
+
+// clampIndex resolves a possibly negative index into [0, length]
+func clampIndex(i, length int) int {
+	if i < 0 {
+		i += length
+	}
+	return max(0, min(i, length))
+}
+
+// numbers sums the numeric elements of an array
+func numbers(v any) (float64, int, error) {
+	items, _ := toSlice(v)
+	total := 0.0
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		n, err := numberArg(item)
+		if err != nil {
+			return 0, 0, err
+		}
+		total += n
+	}
+	return total, len(items), nil
+}
+
+// extreme returns the smallest (sign -1) or largest (sign 1) of its
+// arguments, or of the elements of a single array argument
+func extreme(args []any, sign int) (any, error) {
+	items := args
+	if len(args) == 1 {
+		if s, ok := toSlice(args[0]); ok {
+			items = s
+		}
+	}
+	var best any
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		if best == nil {
+			best = item
+			continue
+		}
+		cmp, ok := Compare(item, best)
+		if !ok {
+			return nil, fmt.Errorf("cannot compare %s and %s", typeName(item), typeName(best))
+		}
+		if cmp*sign > 0 {
+			best = item
+		}
+	}
+	return best, nil
+}
+
+// Stringify converts a value to text. Whole numbers have no decimals, dates
+// use RFC 3339 and objects and arrays are rendered as JSON.
+func Stringify(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case bool:
+		return strconv.FormatBool(val)
+	case time.Time:
+		return val.Format(time.RFC3339)
+	case primitive.ObjectID:
+		return val.Hex()
+	case fmt.Stringer:
+		return val.String()
+	}
+	if n, ok := toNumber(v); ok {
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
+
+// toTime converts dates, RFC 3339 or YYYY-MM-DD strings and Unix
+// milliseconds to a time
+func toTime(v any) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, true
+	case string:
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"} {
+			if parsed, err := time.Parse(layout, t); err == nil {
+				return parsed, true
+			}
+		}
+		return time.Time{}, false
+	}
+	if n, ok := toNumber(v); ok {
+		return time.UnixMilli(int64(n)).UTC(), true
+	}
+	return time.Time{}, false
+}
+
+// dateTokens maps the formatDate tokens to Go layout elements, longest first
+var dateTokens = []struct{ token, layout string }{
+	{"YYYY", "2006"}, {"SSS", "000"}, {"MM", "01"}, {"DD", "02"},
+	{"HH", "15"}, {"mm", "04"}, {"ss", "05"}, {"YY", "06"},
+}
+
+// dateLayout converts a format such as YYYY-MM-DD HH:mm:ss into a Go time
+// layout. RFC3339 and ISO name the standard layouts, and anything without
+// tokens is used as a Go layout as is.
+func dateLayout(format string) string {
+	switch format {
+	case "RFC3339", "ISO":
+		return time.RFC3339
+	case "RFC1123":
+		return time.RFC1123
+	}
+	var b strings.Builder
+	for i := 0; i < len(format); {
+		matched := false
+		for _, t := range dateTokens {
+			if strings.HasPrefix(format[i:], t.token) {
+				b.WriteString(t.layout)
+				i += len(t.token)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			b.WriteByte(format[i])
+			i++
+		}
+	}
+	return b.String()
+}
+
+// parseDuration accepts Go durations plus a d suffix for days, e.g. 7d or -1d12h
+func parseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	sign := time.Duration(1)
+	if strings.HasPrefix(s, "-") {
+		sign = -1
+		s = s[1:]
+	}
+	var days time.Duration
+	if i := strings.Index(s, "d"); i > 0 {
+		n, err := strconv.Atoi(s[:i])
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		days = time.Duration(n) * 24 * time.Hour
+		s = s[i+1:]
+	}
+	var rest time.Duration
+	if s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		rest = d
+	}
+	return sign * (days + rest), nil
+}
@@ -0,0 +1,387 @@
+package expression
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AST nodes
+type (
+	literalNode struct{ value any }
+	identNode   struct{ name string }
+	arrayNode   struct{ items []node }
+
+	// memberNode reads a field, e.g. a.b
+	memberNode struct {
+		target node
+		name   string
+	}
+	// indexNode reads an element or field by a computed key, e.g. a[0] or a["b"]
+	indexNode struct {
+		target node
+		index  node
+	}
+	// wildcardNode selects every element of an array or value of an object, e.g. a[*]
+	wildcardNode struct{ target node }
+	// filterNode keeps the elements matching a predicate, e.g. a[?(@.price > 10)]
+	filterNode struct {
+		target    node
+		predicate node
+	}
+
+	callNode struct {
+		name string
+		fn   function
+		args []node
+	}
+	unaryNode struct {
+		op      string
+		operand node
+	}
+	binaryNode struct {
+		op          string
+		left, right node
+	}
+	conditionalNode struct {
+		cond, then, otherwise node
+	}
+)
+
+type node interface{}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// parse builds the syntax tree of an expression
+func parse(src string) (node, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	n, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %s at position %d", describe(tok), tok.pos)
+	}
+	return n, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// isOp reports whether the next token is one of the given operators. The
+// words and, or and not are accepted for &&, || and !.
+func (p *parser) isOp(ops ...string) (string, bool) {
+	tok := p.peek()
+	text := tok.text
+	if tok.kind == tokIdent {
+		switch text {
+		case "and":
+			text = "&&"
+		case "or":
+			text = "||"
+		case "not":
+			text = "!"
+		default:
+			return "", false
+		}
+	} else if tok.kind != tokOp {
+		return "", false
+	}
+	for _, op := range ops {
+		if text == op {
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) expect(op string) error {
+	tok := p.next()
+	if tok.kind != tokOp || tok.text != op {
+		return fmt.Errorf("expected %q but found %s at position %d", op, describe(tok), tok.pos)
+	}
+	return nil
+}
+
+// parsePipe handles value | fn(args), which calls fn(value, args)
+func (p *parser) parsePipe() (node, error) {
+	left, err := p.parseConditional()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.isOp("|"); !ok {
+			return left, nil
+		}
+		p.next()
+		tok := p.next()
+		if tok.kind != tokIdent {
+			return nil, fmt.Errorf("expected a function name after | at position %d", tok.pos)
+		}
+		args := []node{left}
+		if _, ok := p.isOp("("); ok {
+			p.next()
+			more, err := p.parseArgs()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, more...)
+		}
+		if left, err = newCall(tok, args); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) parseConditional() (node, error) {
+	cond, err := p.parseCoalesce()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := p.isOp("?"); !ok {
+		return cond, nil
+	}
+	p.next()
+	then, err := p.parseConditional()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	otherwise, err := p.parseConditional()
+	if err != nil {
+		return nil, err
+	}
+	return &conditionalNode{cond: cond, then: then, otherwise: otherwise}, nil
+}
+
+// parseBinary parses a left-associative chain of the given operators
+func (p *parser) parseBinary(operand func() (node, error), ops ...string) (node, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.isOp(ops...)
+		if !ok {
+			return left, nil
+		}
+		p.next()
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseCoalesce() (node, error) {
+	return p.parseBinary(p.parseOr, "??")
+}
+
+func (p *parser) parseOr() (node, error) {
+	return p.parseBinary(p.parseAnd, "||")
+}
+
+func (p *parser) parseAnd() (node, error) {
+	return p.parseBinary(p.parseEquality, "&&")
+}
+
+func (p *parser) parseEquality() (node, error) {
+	return p.parseBinary(p.parseComparison, "==", "!=")
+}
+
+func (p *parser) parseComparison() (node, error) {
+	return p.parseBinary(p.parseAdditive, "<", "<=", ">", ">=")
+}
+
+func (p *parser) parseAdditive() (node, error) {
+	return p.parseBinary(p.parseMultiplicative, "+", "-")
+}
+
+func (p *parser) parseMultiplicative() (node, error) {
+	return p.parseBinary(p.parseUnary, "*", "/", "%")
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if op, ok := p.isOp("!", "-"); ok {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: op, operand: operand}, nil
+	}
+	return p.parsePostfix()
+}
+
+// parsePostfix parses field access, indexing, wildcards and filters
+func (p *parser) parsePostfix() (node, error) {
+	target, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch op, _ := p.isOp(".", "["); op {
+		case ".":
+			p.next()
+			tok := p.next()
+			switch tok.kind {
+			case tokIdent:
+				target = &memberNode{target: target, name: tok.text}
+			case tokNumber:
+				target = &indexNode{target: target, index: &literalNode{value: tok.num}}
+			default:
+				return nil, fmt.Errorf("expected a field name after . at position %d", tok.pos)
+			}
+		case "[":
+			p.next()
+			if _, ok := p.isOp("*"); ok {
+				p.next()
+				target = &wildcardNode{target: target}
+			} else if _, ok := p.isOp("?"); ok {
+				p.next()
+				predicate, err := p.parsePipe()
+				if err != nil {
+					return nil, err
+				}
+				target = &filterNode{target: target, predicate: predicate}
+			} else {
+				index, err := p.parsePipe()
+				if err != nil {
+					return nil, err
+				}
+				target = &indexNode{target: target, index: index}
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+		default:
+			return target, nil
+		}
+	}
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokNumber:
+		return &literalNode{value: tok.num}, nil
+	case tokString:
+		return &literalNode{value: tok.text}, nil
+	case tokIdent:
+		switch tok.text {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		case "null", "nil":
+			return &literalNode{value: nil}, nil
+		}
+		if _, ok := p.isOp("("); ok && !strings.HasPrefix(tok.text, "$") && !strings.HasPrefix(tok.text, "@") {
+			p.next()
+			args, err := p.parseArgs()
+			if err != nil {
+				return nil, err
+			}
+			return newCall(tok, args)
+		}
+		return &identNode{name: tok.text}, nil
+	case tokOp:
+		switch tok.text {
+		case "(":
+			inner, err := p.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return inner, nil
+		case "[":
+			items := []node{}
+			if _, ok := p.isOp("]"); ok {
+				p.next()
+				return &arrayNode{items: items}, nil
+			}
+			for {
+				item, err := p.parsePipe()
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, item)
+				if _, ok := p.isOp(","); ok {
+					p.next()
+					continue
+				}
+				if err := p.expect("]"); err != nil {
+					return nil, err
+				}
+				return &arrayNode{items: items}, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("unexpected %s at position %d", describe(tok), tok.pos)
+}
+
+// parseArgs parses call arguments after the opening parenthesis
+func (p *parser) parseArgs() ([]node, error) {
+	args := []node{}
+	if _, ok := p.isOp(")"); ok {
+		p.next()
+		return args, nil
+	}
+	for {
+		arg, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if _, ok := p.isOp(","); ok {
+			p.next()
+			continue
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return args, nil
+	}
+}
+
+func newCall(name token, args []node) (node, error) {
+	fn, ok := functions[name.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s at position %d", name.text, name.pos)
+	}
+	if len(args) < fn.minArgs || (fn.maxArgs >= 0 && len(args) > fn.maxArgs) {
+		return nil, fmt.Errorf("%s expects %s, got %d", name.text, fn.arity(), len(args))
+	}
+	return &callNode{name: name.text, fn: fn, args: args}, nil
+}
+
+func describe(tok token) string {
+	switch tok.kind {
+	case tokEOF:
+		return "end of expression"
+	case tokString:
+		return fmt.Sprintf("string %q", tok.text)
+	default:
+		return fmt.Sprintf("%q", tok.text)
+	}
+}
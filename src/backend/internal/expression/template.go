@@ -0,0 +1,213 @@
+package expression
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// segment is a piece of a template: literal text or a {{ }} placeholder
+type segment struct {
+	text        string // literal text, or the placeholder source including braces
+	expr        string // trimmed expression of a placeholder
+	placeholder bool
+}
+
+// split breaks a template into literal text and placeholders. Braces inside
+// quoted strings in a placeholder do not close it. Block helpers such as
+// {{#each}}, {{/if}} and {{else}} are kept as literal text.
+func split(template string) ([]segment, error) {
+	var segments []segment
+	rest := template
+	for {
+		start := strings.Index(rest, "{{")
+		if start < 0 {
+			if rest != "" {
+				segments = append(segments, segment{text: rest})
+			}
+			return segments, nil
+		}
+		end := closingBraces(rest, start+2)
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed {{ in template")
+		}
+		if start > 0 {
+			segments = append(segments, segment{text: rest[:start]})
+		}
+		raw := rest[start : end+2]
+		expr := strings.TrimSpace(rest[start+2 : end])
+		if isBlockHelper(expr) {
+			segments = append(segments, segment{text: raw})
+		} else {
+			segments = append(segments, segment{text: raw, expr: expr, placeholder: true})
+		}
+		rest = rest[end+2:]
+	}
+}
+
+// closingBraces returns the index of the }} closing a placeholder whose
+// content starts at from, or -1
+func closingBraces(s string, from int) int {
+	var quote byte
+	for i := from; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '}' && i+1 < len(s) && s[i+1] == '}':
+			return i
+		}
+	}
+	return -1
+}
+
+func isBlockHelper(expr string) bool {
+	return strings.HasPrefix(expr, "#") || strings.HasPrefix(expr, "/") || expr == "else"
+}
+
+// IsTemplate reports whether s contains {{ }} placeholders
+func IsTemplate(s string) bool {
+	return strings.Contains(s, "{{")
+}
+
+// Render replaces every placeholder with the text of its value. Missing
+// values render as nothing and objects and arrays as JSON.
+func Render(template string, scope *Scope) (string, error) {
+	if !IsTemplate(template) {
+		return template, nil
+	}
+	segments, err := split(template)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, seg := range segments {
+		if !seg.placeholder {
+			b.WriteString(seg.text)
+			continue
+		}
+		value, err := Eval(seg.expr, scope)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(Stringify(value))
+	}
+	return b.String(), nil
+}
+
+// Resolve evaluates a template that may hold a value of any type. A template
+// made of a single placeholder returns its value as is; anything else is
+// rendered to a string.
+func Resolve(template string, scope *Scope) (any, error) {
+	segments, err := split(template)
+	if err != nil {
+		return nil, err
+	}
+	if len(segments) == 1 && segments[0].placeholder {
+		return Eval(segments[0].expr, scope)
+	}
+	return Render(template, scope)
+}
+
+// RenderJSON fills the placeholders of a JSON document. A placeholder inside
+// a string inserts escaped text, so "id": "{{order.id}}" stays a string; a
+// placeholder outside strings, as in "total": {{order.total}}, inserts the
+// JSON value including its type.
+func RenderJSON(template string, scope *Scope) (string, error) {
+	return renderJSON(template, scope, false)
+}
+
+// RenderJSONValues is like RenderJSON, except that a placeholder that is a
+// whole JSON string, as in "total": "{{order.total}}", is also replaced with
+// the JSON value including its type
+func RenderJSONValues(template string, scope *Scope) (string, error) {
+	return renderJSON(template, scope, true)
+}
+
+func renderJSON(template string, scope *Scope, typedStrings bool) (string, error) {
+	if !IsTemplate(template) {
+		return template, nil
+	}
+	segments, err := split(template)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	inString := false
+	for i, seg := range segments {
+		if !seg.placeholder {
+			b.WriteString(seg.text)
+			inString = scanJSONStrings(seg.text, inString)
+			continue
+		}
+
+		value, err := Eval(seg.expr, scope)
+		if err != nil {
+			return "", err
+		}
+
+		if !inString {
+			encoded, err := marshalJSON(value)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(encoded)
+			continue
+		}
+
+		// A whole-string placeholder replaces the surrounding quotes
+		current := b.String()
+		opensString := strings.HasSuffix(current, `"`) && !strings.HasSuffix(current, `\"`)
+		closesString := i+1 < len(segments) && !segments[i+1].placeholder && strings.HasPrefix(segments[i+1].text, `"`)
+		if typedStrings && opensString && closesString {
+			encoded, err := marshalJSON(value)
+			if err != nil {
+				return "", err
+			}
+			b.Reset()
+			b.WriteString(current[:len(current)-1])
+			b.WriteString(encoded)
+			segments[i+1].text = segments[i+1].text[1:]
+			inString = false
+			continue
+		}
+
+		escaped, err := json.Marshal(Stringify(value))
+		if err != nil {
+			return "", err
+		}
+		b.Write(escaped[1 : len(escaped)-1])
+	}
+	return b.String(), nil
+}
+
+// scanJSONStrings reports whether the text leaves a JSON string open, given
+// whether one was open at its start
+func scanJSONStrings(text string, inString bool) bool {
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			if inString {
+				i++
+			}
+		case '"':
+			inString = !inString
+		}
+	}
+	return inString
+}
+
+func marshalJSON(v any) (string, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("cannot encode %s as JSON: %w", typeName(v), err)
+	}
+	return string(encoded), nil
+}
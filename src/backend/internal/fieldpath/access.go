@@ -0,0 +1,346 @@
+package fieldpath
+
+import (
+	"fmt"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Get reads the value at the path, or nil if it does not exist. Paths with
+// wildcards return an array of the values found.
+func (p *Path) Get(data any) any {
+	if !p.HasWildcard() {
+		current := data
+		for _, seg := range p.segments {
+			if current = step(current, seg); current == nil {
+				return nil
+			}
+		}
+		return current
+	}
+
+	matches := []any{data}
+	for _, seg := range p.segments {
+		next := []any{}
+		for _, m := range matches {
+			if seg.kind == segWildcard {
+				next = append(next, children(m)...)
+				continue
+			}
+			if seg.kind == segDescendant {
+				next = append(next, descendants(m)...)
+				continue
+			}
+			if v := step(m, seg); v != nil {
+				next = append(next, v)
+			}
+		}
+		matches = next
+	}
+	return matches
+}
+
+// step follows a key or index segment
+func step(current any, seg segment) any {
+	switch seg.kind {
+	case segKey:
+		switch m := current.(type) {
+		case map[string]any:
+			return m[seg.key]
+		case primitive.M:
+			return m[seg.key]
+		case primitive.D:
+			for _, e := range m {
+				if e.Key == seg.key {
+					return e.Value
+				}
+			}
+		}
+	case segIndex:
+		items := asArray(current)
+		i := seg.index
+		if i < 0 {
+			i += len(items)
+		}
+		if i >= 0 && i < len(items) {
+			return items[i]
+		}
+	}
+	return nil
+}
+
+// children returns the elements of an array or the values of an object,
+// ordered by key
+func children(v any) []any {
+	if items := asArray(v); items != nil {
+		return items
+	}
+	var m map[string]any
+	switch obj := v.(type) {
+	case map[string]any:
+		m = obj
+	case primitive.M:
+		m = obj
+	case primitive.D:
+		m = obj.Map()
+	default:
+		return nil
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := make([]any, len(keys))
+	for i, k := range keys {
+		out[i] = m[k]
+	}
+	return out
+}
+
+// descendants returns a value followed by everything nested in it
+func descendants(v any) []any {
+	out := []any{v}
+	for _, child := range children(v) {
+		out = append(out, descendants(child)...)
+	}
+	return out
+}
+
+func asArray(v any) []any {
+	switch a := v.(type) {
+	case []any:
+		return a
+	case primitive.A:
+		return a
+	case []map[string]any:
+		out := make([]any, len(a))
+		for i, item := range a {
+			out[i] = item
+		}
+		return out
+	}
+	return nil
+}
+
+// Set writes a value at the path. Missing objects are created, as are
+// arrays where the next segment is an index; arrays grow to fit the index
+// with null padding. A wildcard writes to every existing element.
+func (p *Path) Set(data map[string]any, value any) error {
+	if len(p.segments) == 0 {
+		return fmt.Errorf("cannot replace the root with path %q", p.src)
+	}
+	if p.segments[0].kind != segKey {
+		return fmt.Errorf("path %q must start with a field name", p.src)
+	}
+	for _, seg := range p.segments {
+		if seg.kind == segDescendant {
+			return fmt.Errorf("cannot write to path %q: .. is read-only", p.src)
+		}
+	}
+	_, err := set(data, p.segments, value)
+	return err
+}
+
+// maxArrayGrowth is how many elements writing past the end of an array may
+// add, so a path such as items[100000000] cannot exhaust memory
+const maxArrayGrowth = 1000
+
+// set writes value below current and returns the container to store in
+// place of current, which differs when an array had to grow or be created
+func set(current any, segments []segment, value any) (any, error) {
+	if len(segments) == 0 {
+		return value, nil
+	}
+	seg, rest := segments[0], segments[1:]
+
+	switch seg.kind {
+	case segKey:
+		m, ok := current.(map[string]any)
+		if !ok {
+			m = make(map[string]any)
+		}
+		child, err := set(m[seg.key], rest, value)
+		if err != nil {
+			return nil, err
+		}
+		m[seg.key] = child
+		return m, nil
+
+	case segIndex:
+		items, _ := current.([]any)
+		i := seg.index
+		if i < 0 {
+			i += len(items)
+			if i < 0 {
+				return nil, fmt.Errorf("index %d is out of range for %d elements", seg.index, len(items))
+			}
+		}
+		if i-len(items) >= maxArrayGrowth {
+			return nil, fmt.Errorf("index %d is too far past the end of %d elements: arrays grow by at most %d", i, len(items), maxArrayGrowth)
+		}
+		for len(items) <= i {
+			items = append(items, nil)
+		}
+		child, err := set(items[i], rest, value)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = child
+		return items, nil
+
+	default:
+		switch c := current.(type) {
+		case []any:
+			for i := range c {
+				child, err := set(c[i], rest, value)
+				if err != nil {
+					return nil, err
+				}
+				c[i] = child
+			}
+			return c, nil
+		case map[string]any:
+			for k, v := range c {
+				child, err := set(v, rest, value)
+				if err != nil {
+					return nil, err
+				}
+				c[k] = child
+			}
+			return c, nil
+		}
+		return nil, fmt.Errorf("wildcard has no array or object to write to")
+	}
+}
+
+// Replace returns data with every value the path matches replaced by the
+// result of fn. Unlike Set it never creates fields and never changes data:
+// the objects and arrays leading to a match are copied and the rest is
+// shared with the original.
+func (p *Path) Replace(data any, fn func(any) any) any {
+	replaced, _ := replace(data, p.segments, fn)
+	return replaced
+}
+
+// replace returns the replaced value and whether anything matched
+func replace(current any, segments []segment, fn func(any) any) (any, bool) {
+	if len(segments) == 0 {
+		return fn(current), true
+	}
+	seg, rest := segments[0], segments[1:]
+
+	switch seg.kind {
+	case segKey:
+		child := step(current, seg)
+		if child == nil {
+			return current, false
+		}
+		replaced, changed := replace(child, rest, fn)
+		if !changed {
+			return current, false
+		}
+		return withField(current, seg.key, replaced), true
+
+	case segIndex:
+		items := asArray(current)
+		i := seg.index
+		if i < 0 {
+			i += len(items)
+		}
+		if i < 0 || i >= len(items) {
+			return current, false
+		}
+		replaced, changed := replace(items[i], rest, fn)
+		if !changed {
+			return current, false
+		}
+		out := append([]any(nil), items...)
+		out[i] = replaced
+		return out, true
+
+	case segWildcard:
+		return replaceChildren(current, func(child any) (any, bool) {
+			return replace(child, rest, fn)
+		})
+
+	default:
+		// The rest of the path may match the value itself or anything below it
+		self, changed := replace(current, rest, fn)
+		replaced, childChanged := replaceChildren(self, func(child any) (any, bool) {
+			return replace(child, segments, fn)
+		})
+		return replaced, changed || childChanged
+	}
+}
+
+// replaceChildren applies fn to every element of an array or field of an
+// object and returns a copy if any of them changed
+func replaceChildren(current any, fn func(any) (any, bool)) (any, bool) {
+	if items := asArray(current); items != nil {
+		var out []any
+		for i, item := range items {
+			if replaced, changed := fn(item); changed {
+				if out == nil {
+					out = append([]any(nil), items...)
+				}
+				out[i] = replaced
+			}
+		}
+		if out == nil {
+			return current, false
+		}
+		return out, true
+	}
+
+	var fields map[string]any
+	switch obj := current.(type) {
+	case map[string]any:
+		fields = obj
+	case primitive.M:
+		fields = obj
+	case primitive.D:
+		fields = obj.Map()
+	default:
+		return current, false
+	}
+	changed := false
+	for key, value := range fields {
+		if replaced, ok := fn(value); ok {
+			current = withField(current, key, replaced)
+			changed = true
+		}
+	}
+	return current, changed
+}
+
+// withField returns a copy of an object with one field set
+func withField(obj any, key string, value any) any {
+	switch m := obj.(type) {
+	case primitive.D:
+		out := make(primitive.D, len(m))
+		copy(out, m)
+		for i := range out {
+			if out[i].Key == key {
+				out[i].Value = value
+			}
+		}
+		return out
+	case primitive.M:
+		out := make(primitive.M, len(m))
+		for k, v := range m {
+			out[k] = v
+		}
+		out[key] = value
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(m))
+		for k, v := range m {
+			out[k] = v
+		}
+		out[key] = value
+		return out
+	}
+	return obj
+}
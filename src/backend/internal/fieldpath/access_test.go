@@ -0,0 +1,33 @@
+package fieldpath
+
+import (
+	"testing"
+)
+
+func TestSetPadsArrays(t *testing.T) {
+	data := map[string]any{}
+	if err := Set(data, "lines[2].sku", "A-1"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	lines, ok := data["lines"].([]any)
+	if !ok || len(lines) != 3 || lines[0] != nil || lines[1] != nil {
+		t.Fatalf("Set() lines = %v, want two nulls and the new element", data["lines"])
+	}
+}
+
+func TestSetRejectsIndexFarPastEnd(t *testing.T) {
+	data := map[string]any{"items": []any{"a"}}
+	if err := Set(data, "items[100000000]", "x"); err == nil {
+		t.Fatal("Set(items[100000000]) error = nil, want an error")
+	}
+	if items := data["items"].([]any); len(items) != 1 {
+		t.Fatalf("Set(items[100000000]) grew items to %d elements", len(items))
+	}
+
+	if err := Set(data, "items[1000]", "x"); err != nil {
+		t.Fatalf("Set(items[1000]) error = %v", err)
+	}
+	if items := data["items"].([]any); len(items) != 1001 {
+		t.Fatalf("Set(items[1000]) items has %d elements, want 1001", len(items))
+	}
+}
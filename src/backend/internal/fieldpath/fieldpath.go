@@ -0,0 +1,221 @@
+// Package fieldpath reads and writes values in nested JSON-like data using
+// field paths such as:
+//
+//	customer.email
+//	items[0].sku
+//	items[-1]             (last element)
+//	items[*].price        (every element)
+//	*.id                  (every field of an object)
+//	..password            (at any depth)
+//	headers["x.request.id"] or headers['x-request-id']
+//	$.items[0]            (JSONPath style root)
+//
+// Reads through a wildcard or descendant return an array of the matches.
+// Writes create missing objects, and arrays where the next segment is an
+// index.
+package fieldpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type segmentKind int
+
+const (
+	segKey segmentKind = iota
+	segIndex
+	segWildcard
+	segDescendant // the current value and everything nested in it
+)
+
+type segment struct {
+	kind  segmentKind
+	key   string
+	index int
+}
+
+// Path is a parsed field path
+type Path struct {
+	src      string
+	segments []segment
+}
+
+// String returns the path as written
+func (p *Path) String() string {
+	return p.src
+}
+
+// HasWildcard reports whether the path can match several values
+func (p *Path) HasWildcard() bool {
+	for _, seg := range p.segments {
+		if seg.kind == segWildcard || seg.kind == segDescendant {
+			return true
+		}
+	}
+	return false
+}
+
+var cache sync.Map // source -> *Path
+
+// maxCached bounds the number of parsed paths kept in memory
+const maxCached = 10000
+
+var (
+	cacheSize int
+	cacheMu   sync.Mutex
+)
+
+// Parse parses a field path. Parsed paths are cached.
+func Parse(src string) (*Path, error) {
+	if cached, ok := cache.Load(src); ok {
+		return cached.(*Path), nil
+	}
+
+	segments, err := parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path %q: %w", src, err)
+	}
+	p := &Path{src: src, segments: segments}
+
+	cacheMu.Lock()
+	if cacheSize < maxCached {
+		if _, loaded := cache.LoadOrStore(src, p); !loaded {
+			cacheSize++
+		}
+	}
+	cacheMu.Unlock()
+	return p, nil
+}
+
+// Get reads the value at a path, or nil if it does not exist
+func Get(data any, path string) (any, error) {
+	p, err := Parse(path)
+	if err != nil {
+		return nil, err
+	}
+	return p.Get(data), nil
+}
+
+// Set writes a value at a path, creating the objects and arrays on the way
+func Set(data map[string]any, path string, value any) error {
+	p, err := Parse(path)
+	if err != nil {
+		return err
+	}
+	return p.Set(data, value)
+}
+
+func parse(src string) ([]segment, error) {
+	s := strings.TrimSpace(src)
+	if s == "" {
+		return nil, fmt.Errorf("path is empty")
+	}
+
+	// JSONPath style root
+	if s == "$" {
+		return []segment{}, nil
+	}
+	if strings.HasPrefix(s, "$..") {
+		s = s[1:]
+	} else if strings.HasPrefix(s, "$.") || strings.HasPrefix(s, "$[") {
+		s = strings.TrimPrefix(s[1:], ".")
+	}
+
+	var segments []segment
+	i := 0
+	expectKey := true // a key may follow the start of the path or a dot
+	for i < len(s) {
+		switch c := s[i]; {
+		case c == '[':
+			seg, end, err := parseBracket(s, i)
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, seg)
+			i = end
+			expectKey = false
+
+		case c == '.' && strings.HasPrefix(s[i:], ".."):
+			segments = append(segments, segment{kind: segDescendant})
+			i += 2
+			expectKey = true
+			if i == len(s) {
+				return nil, fmt.Errorf("path ends with ..")
+			}
+
+		case c == '.':
+			if expectKey {
+				return nil, fmt.Errorf("empty field name at position %d", i)
+			}
+			i++
+			expectKey = true
+			if i == len(s) {
+				return nil, fmt.Errorf("path ends with a dot")
+			}
+
+		default:
+			if !expectKey {
+				return nil, fmt.Errorf("expected . or [ at position %d", i)
+			}
+			start := i
+			for i < len(s) && s[i] != '.' && s[i] != '[' {
+				i++
+			}
+			name := s[start:i]
+			if name == "*" {
+				segments = append(segments, segment{kind: segWildcard})
+			} else {
+				segments = append(segments, segment{kind: segKey, key: name})
+			}
+			expectKey = false
+		}
+	}
+	return segments, nil
+}
+
+// parseBracket parses [0], [-1], [*], ["key"] or ['key'] starting at s[start]
+// and returns the position after the closing bracket
+func parseBracket(s string, start int) (segment, int, error) {
+	i := start + 1
+	if i < len(s) && (s[i] == '"' || s[i] == '\'') {
+		quote := s[i]
+		var b strings.Builder
+		for i++; i < len(s); i++ {
+			c := s[i]
+			if c == '\\' && i+1 < len(s) {
+				i++
+				b.WriteByte(s[i])
+				continue
+			}
+			if c == quote {
+				break
+			}
+			b.WriteByte(c)
+		}
+		if i >= len(s) {
+			return segment{}, 0, fmt.Errorf("unterminated quoted key at position %d", start)
+		}
+		if i+1 >= len(s) || s[i+1] != ']' {
+			return segment{}, 0, fmt.Errorf("expected ] at position %d", i+1)
+		}
+		return segment{kind: segKey, key: b.String()}, i + 2, nil
+	}
+
+	end := strings.IndexByte(s[i:], ']')
+	if end < 0 {
+		return segment{}, 0, fmt.Errorf("unclosed [ at position %d", start)
+	}
+	content := strings.TrimSpace(s[i : i+end])
+	next := i + end + 1
+	if content == "*" {
+		return segment{kind: segWildcard}, next, nil
+	}
+	index, err := strconv.Atoi(content)
+	if err != nil {
+		return segment{}, 0, fmt.Errorf("invalid index %q at position %d", content, start)
+	}
+	return segment{kind: segIndex, index: index}, next, nil
+}
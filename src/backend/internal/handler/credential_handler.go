@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nodetl/nodetl/internal/domain"
+	"github.com/nodetl/nodetl/internal/middleware"
+	"github.com/nodetl/nodetl/internal/repository"
+	"github.com/nodetl/nodetl/internal/service"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CredentialHandler handles credential endpoints. Responses never include
+// secret values.
+type CredentialHandler struct {
+	credentialService *service.CredentialService
+}
+
+// NewCredentialHandler creates a new credential handler
+func NewCredentialHandler(credentialService *service.CredentialService) *CredentialHandler {
+	return &CredentialHandler{credentialService: credentialService}
+}
+
+// ListCredentials returns all credentials
+func (h *CredentialHandler) ListCredentials(c *gin.Context) {
+	credentials, err := h.credentialService.ListCredentials(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list credentials"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    credentials,
+		"total":   len(credentials),
+		"enabled": h.credentialService.Enabled(),
+	})
+}
+
+// ListCredentialTypes returns the supported credential types
+func (h *CredentialHandler) ListCredentialTypes(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": domain.CredentialTypes()})
+}
+
+// GetCredential returns a credential
+func (h *CredentialHandler) GetCredential(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid credential ID"})
+		return
+	}
+
+	credential, err := h.credentialService.GetCredential(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err, "failed to get credential")
+		return
+	}
+
+	c.JSON(http.StatusOK, credential)
+}
+
+// CreateCredential stores a new credential
+func (h *CredentialHandler) CreateCredential(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+
+	var req domain.CreateCredentialRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	credential, err := h.credentialService.CreateCredential(c.Request.Context(), &req, userID)
+	if err != nil {
+		h.handleError(c, err, "failed to create credential")
+		return
+	}
+
+	c.JSON(http.StatusCreated, credential)
+}
+
+// UpdateCredential changes a credential
+func (h *CredentialHandler) UpdateCredential(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid credential ID"})
+		return
+	}
+
+	var req domain.UpdateCredentialRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	credential, err := h.credentialService.UpdateCredential(c.Request.Context(), id, &req)
+	if err != nil {
+		h.handleError(c, err, "failed to update credential")
+		return
+	}
+
+	c.JSON(http.StatusOK, credential)
+}
+
+// DeleteCredential removes a credential
+func (h *CredentialHandler) DeleteCredential(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid credential ID"})
+		return
+	}
+
+	if err := h.credentialService.DeleteCredential(c.Request.Context(), id); err != nil {
+		h.handleError(c, err, "failed to delete credential")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "credential deleted"})
+}
+
+// handleError maps service errors to responses
+func (h *CredentialHandler) handleError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, repository.ErrCredentialNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "credential not found"})
+	case errors.Is(err, repository.ErrCredentialAlreadyExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidCredential):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrCredentialsDisabled):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
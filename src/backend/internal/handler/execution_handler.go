@@ -1,12 +1,19 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/nodetl/nodetl/internal/domain"
 	"github.com/nodetl/nodetl/internal/executor"
 	"github.com/nodetl/nodetl/internal/repository"
+	"github.com/nodetl/nodetl/internal/tracing"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -14,24 +21,29 @@ type ExecutionHandler struct {
 	repo         repository.ExecutionRepository
 	workflowRepo repository.WorkflowRepository
 	flowExecutor *executor.FlowExecutor
+	workerPool   *executor.WorkerPool
 }
 
 func NewExecutionHandler(
 	repo repository.ExecutionRepository,
 	workflowRepo repository.WorkflowRepository,
 	flowExecutor *executor.FlowExecutor,
+	workerPool *executor.WorkerPool,
 ) *ExecutionHandler {
 	return &ExecutionHandler{
 		repo:         repo,
 		workflowRepo: workflowRepo,
 		flowExecutor: flowExecutor,
+		workerPool:   workerPool,
 	}
 }
 
 // ExecuteRequest is the request body for manual workflow execution
 type ExecuteRequest struct {
-	Input    map[string]any `json:"input"`
-	Metadata map[string]any `json:"metadata,omitempty"`
+	Input       map[string]any `json:"input"`
+	Metadata    map[string]any `json:"metadata,omitempty"`
+	Async       bool           `json:"async,omitempty"`       // Queue the execution and return 202 immediately
+	Environment string         `json:"environment,omitempty"` // Project environment to run in instead of the trigger's
 }
 
 // ExecuteWorkflow manually executes a workflow
@@ -48,21 +60,57 @@ func (h *ExecutionHandler) ExecuteWorkflow(c *gin.Context) {
 		req.Input = make(map[string]any)
 	}
 
-	result, err := h.flowExecutor.Execute(c.Request.Context(), &executor.ExecuteRequest{
+	execReq := &executor.ExecuteRequest{
 		WorkflowID:  workflowID,
 		TriggerType: "manual",
+		Environment: req.Environment,
 		Input:       req.Input,
 		Metadata:    req.Metadata,
-	})
+	}
+	if env := c.Query("environment"); env != "" {
+		execReq.Environment = env
+	}
+	// Continue the caller's trace when it sends a traceparent header
+	ctx := tracing.Extract(c.Request.Context(), c.Request.Header)
+
+	if req.Async || c.Query("async") == "true" {
+		result, err := h.workerPool.Submit(ctx, execReq)
+		if err != nil {
+			c.JSON(executeErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusAccepted, acceptedResponse(result))
+		return
+	}
+
+	result, err := h.flowExecutor.Execute(ctx, execReq)
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(executeErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, result)
 }
 
+// executeErrorStatus returns the status code for an execution that could not
+// be started
+func executeErrorStatus(err error) int {
+	if errors.Is(err, executor.ErrUnknownEnvironment) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// acceptedResponse is returned for executions queued to run in the background
+func acceptedResponse(result *executor.ExecuteResult) gin.H {
+	return gin.H{
+		"executionId": result.ExecutionID,
+		"status":      result.Status,
+		"statusUrl":   "/api/v1/executions/" + result.ExecutionID,
+	}
+}
+
 // GetExecution gets an execution by ID
 func (h *ExecutionHandler) GetExecution(c *gin.Context) {
 	id, err := primitive.ObjectIDFromHex(c.Param("id"))
@@ -81,27 +129,170 @@ func (h *ExecutionHandler) GetExecution(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, execution)
+	c.JSON(http.StatusOK, h.flowExecutor.RedactExecution(c.Request.Context(), execution))
 }
 
-// ListExecutions lists executions for a workflow
-func (h *ExecutionHandler) ListExecutions(c *gin.Context) {
-	workflowID, err := primitive.ObjectIDFromHex(c.Param("id"))
+// streamHeartbeat is how often an idle event stream sends a keep-alive comment
+const streamHeartbeat = 15 * time.Second
+
+// StreamExecution streams the progress events of an execution as Server-Sent
+// Events. Events emitted before the client connected are replayed first;
+// clients resuming with Last-Event-ID only receive the events they missed.
+func (h *ExecutionHandler) StreamExecution(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid workflow ID"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid execution ID"})
 		return
 	}
 
-	executions, total, err := h.repo.GetByWorkflowID(c.Request.Context(), workflowID, 1, 50)
+	after := 0
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("lastEventId")
+	}
+	if lastEventID != "" {
+		if val, err := strconv.Atoi(lastEventID); err == nil && val > 0 {
+			after = val
+		}
+	}
+
+	// Subscribe before reading the execution so no event is missed in between
+	past, events, unsubscribe := h.flowExecutor.Events().Subscribe(id.Hex(), after)
+	defer unsubscribe()
+
+	execution, err := h.repo.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	if execution == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "execution not found"})
+		return
+	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"data":  executions,
-		"total": total,
-	})
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	seen := after
+	send := func(event domain.ExecutionEvent) {
+		if event.Seq <= seen {
+			return
+		}
+		seen = event.Seq
+		data, err := json.Marshal(event)
+		if err != nil {
+			return
+		}
+		fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.Seq, event.Type, data)
+		c.Writer.Flush()
+	}
+	// Executions this instance holds no events for, because they finished a
+	// while ago or ran elsewhere, are replayed from the stored record
+	sendRecorded := func(execution *domain.Execution) bool {
+		if execution.Status == domain.ExecutionStatusPending || execution.Status == domain.ExecutionStatusRunning {
+			return false
+		}
+		for _, event := range executor.RecordedEvents(h.flowExecutor.RedactExecution(c.Request.Context(), execution)) {
+			send(event)
+		}
+		return true
+	}
+
+	for _, event := range past {
+		send(event)
+	}
+	if len(past) == 0 && seen == 0 && sendRecorded(execution) {
+		return
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			send(event)
+		case <-heartbeat.C:
+			if seen == 0 {
+				execution, err := h.repo.GetByID(c.Request.Context(), id)
+				if err == nil && execution != nil && sendRecorded(execution) {
+					return
+				}
+			}
+			fmt.Fprint(c.Writer, ": keep-alive\n\n")
+			c.Writer.Flush()
+		}
+	}
+}
+
+// CancelExecution cancels a running or queued execution
+func (h *ExecutionHandler) CancelExecution(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid execution ID"})
+		return
+	}
+
+	if err := h.flowExecutor.Cancel(c.Request.Context(), id); err != nil {
+		switch {
+		case errors.Is(err, executor.ErrExecutionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, executor.ErrExecutionFinished):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "execution cancellation requested"})
+}
+
+// ReplayExecution re-runs an execution against the current workflow definition,
+// optionally starting from a node with the input recorded for it
+func (h *ExecutionHandler) ReplayExecution(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid execution ID"})
+		return
+	}
+
+	result, err := h.flowExecutor.Replay(c.Request.Context(), id, c.Query("fromNode"))
+	if err != nil {
+		switch {
+		case errors.Is(err, executor.ErrExecutionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, executor.ErrExecutionNotFinished):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, executor.ErrReplayNode):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ListExecutions lists and searches the executions of a workflow
+func (h *ExecutionHandler) ListExecutions(c *gin.Context) {
+	workflowID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid workflow ID"})
+		return
+	}
+
+	h.searchExecutions(c, []primitive.ObjectID{workflowID})
 }
 
 // GetLatestExecutions gets the latest executions for a workflow
@@ -117,31 +308,222 @@ func (h *ExecutionHandler) GetLatestExecutions(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	h.flowExecutor.RedactExecutions(c.Request.Context(), executions)
 
 	c.JSON(http.StatusOK, executions)
 }
 
-// ListExecutionsByProject lists all executions for workflows in a project
-func (h *ExecutionHandler) ListExecutionsByProject(c *gin.Context) {
-	projectIDStr := c.Param("id")
-	// Validate it's a valid ObjectID format
-	if _, err := primitive.ObjectIDFromHex(projectIDStr); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+// DeleteExecutions deletes the finished executions of a workflow started
+// before the time given by ?before=, as RFC 3339 or a YYYY-MM-DD date
+func (h *ExecutionHandler) DeleteExecutions(c *gin.Context) {
+	workflowID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid workflow ID"})
 		return
 	}
 
-	// Parse pagination params
-	page := 1
-	pageSize := 50
+	before, err := parseBefore(c.Query("before"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	deleted, err := h.repo.DeleteFinishedBefore(c.Request.Context(), workflowID, before)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "executions deleted",
+		"deleted": deleted,
+	})
+}
+
+// parseBefore parses the before query parameter
+func parseBefore(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, fmt.Errorf("before is required")
+	}
+	return parseTime("before", value)
+}
+
+// parseTime parses a query parameter given as RFC 3339 or a YYYY-MM-DD date
+func parseTime(name, value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%s must be an RFC 3339 time or a YYYY-MM-DD date", name)
+}
+
+// parseDuration parses a query parameter given in milliseconds or as a Go
+// duration such as 1.5s, and returns it in milliseconds
+func parseDuration(name, value string) (int64, error) {
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil && ms >= 0 {
+		return ms, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return d.Milliseconds(), nil
+	}
+	return 0, fmt.Errorf("%s must be a number of milliseconds or a duration such as 1.5s", name)
+}
+
+// queryList returns the values of a query parameter that may be repeated or
+// comma-separated
+func queryList(c *gin.Context, name string) []string {
+	var values []string
+	for _, param := range c.QueryArray(name) {
+		for _, value := range strings.Split(param, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// executionFilter reads the search options of an execution listing from the
+// query string
+func executionFilter(c *gin.Context) (repository.ExecutionFilter, error) {
+	filter := repository.ExecutionFilter{
+		TriggerTypes: queryList(c, "trigger"),
+		TraceID:      c.Query("traceId"),
+		FailedNode:   c.Query("failedNode"),
+		ErrorText:    c.Query("q"),
+		Cursor:       c.Query("cursor"),
+		Page:         1,
+		PageSize:     50,
+	}
+
+	for _, status := range queryList(c, "status") {
+		switch s := domain.ExecutionStatus(status); s {
+		case domain.ExecutionStatusPending, domain.ExecutionStatusRunning, domain.ExecutionStatusCompleted,
+			domain.ExecutionStatusFailed, domain.ExecutionStatusCancelled:
+			filter.Statuses = append(filter.Statuses, s)
+		default:
+			return filter, fmt.Errorf("unknown status %q", status)
+		}
+	}
+
+	if v := c.Query("from"); v != "" {
+		t, err := parseTime("from", v)
+		if err != nil {
+			return filter, err
+		}
+		filter.StartedFrom = &t
+	}
+	if v := c.Query("to"); v != "" {
+		t, err := parseTime("to", v)
+		if err != nil {
+			return filter, err
+		}
+		filter.StartedTo = &t
+	}
+	if v := c.Query("minDuration"); v != "" {
+		ms, err := parseDuration("minDuration", v)
+		if err != nil {
+			return filter, err
+		}
+		filter.MinDuration = &ms
+	}
+	if v := c.Query("maxDuration"); v != "" {
+		ms, err := parseDuration("maxDuration", v)
+		if err != nil {
+			return filter, err
+		}
+		filter.MaxDuration = &ms
+	}
+
+	switch sortBy := c.DefaultQuery("sort", repository.ExecutionSortStartedAt); sortBy {
+	case repository.ExecutionSortStartedAt, repository.ExecutionSortDuration:
+		filter.SortBy = sortBy
+	default:
+		return filter, fmt.Errorf("sort must be %s or %s", repository.ExecutionSortStartedAt, repository.ExecutionSortDuration)
+	}
+	switch c.DefaultQuery("order", "desc") {
+	case "desc":
+	case "asc":
+		filter.Ascending = true
+	default:
+		return filter, fmt.Errorf("order must be asc or desc")
+	}
+
 	if p := c.Query("page"); p != "" {
 		if val, err := strconv.Atoi(p); err == nil && val > 0 {
-			page = val
+			filter.Page = val
 		}
 	}
-	if ps := c.Query("pageSize"); ps != "" {
-		if val, err := strconv.Atoi(ps); err == nil && val > 0 && val <= 100 {
-			pageSize = val
+	pageSize := c.Query("limit")
+	if pageSize == "" {
+		pageSize = c.Query("pageSize")
+	}
+	if pageSize != "" {
+		if val, err := strconv.Atoi(pageSize); err == nil && val > 0 && val <= 100 {
+			filter.PageSize = val
+		}
+	}
+
+	return filter, nil
+}
+
+// searchExecutions responds with the executions of the given workflows that
+// match the query string
+func (h *ExecutionHandler) searchExecutions(c *gin.Context, workflowIDs []primitive.ObjectID) {
+	filter, err := executionFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter.WorkflowIDs = workflowIDs
+
+	page, err := h.repo.Search(c.Request.Context(), filter)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
 		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	h.flowExecutor.RedactExecutions(c.Request.Context(), page.Executions)
+
+	response := gin.H{
+		"data":     page.Executions,
+		"total":    page.Total,
+		"pageSize": filter.PageSize,
+	}
+	if page.NextCursor != "" {
+		response["nextCursor"] = page.NextCursor
+	}
+	if filter.Cursor == "" {
+		response["page"] = filter.Page
+		response["totalPages"] = (page.Total + int64(filter.PageSize) - 1) / int64(filter.PageSize)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// ListExecutionsByProject lists and searches the executions of all workflows
+// in a project
+func (h *ExecutionHandler) ListExecutionsByProject(c *gin.Context) {
+	workflowIDs, ok := h.projectWorkflowIDs(c)
+	if !ok {
+		return
+	}
+
+	h.searchExecutions(c, workflowIDs)
+}
+
+// projectWorkflowIDs returns the IDs of the workflows in the project of the
+// request, or responds with an error
+func (h *ExecutionHandler) projectWorkflowIDs(c *gin.Context) ([]primitive.ObjectID, bool) {
+	projectIDStr := c.Param("id")
+	// Validate it's a valid ObjectID format
+	if _, err := primitive.ObjectIDFromHex(projectIDStr); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project ID"})
+		return nil, false
 	}
 
 	// Get all workflows in this project
@@ -153,7 +535,7 @@ func (h *ExecutionHandler) ListExecutionsByProject(c *gin.Context) {
 	workflows, _, err := h.workflowRepo.GetAll(c.Request.Context(), filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
+		return nil, false
 	}
 
 	// Collect workflow IDs
@@ -161,19 +543,139 @@ func (h *ExecutionHandler) ListExecutionsByProject(c *gin.Context) {
 	for i, w := range workflows {
 		workflowIDs[i] = w.ID
 	}
+	return workflowIDs, true
+}
+
+// GetWorkflowStats summarizes the executions of a workflow
+func (h *ExecutionHandler) GetWorkflowStats(c *gin.Context) {
+	workflowID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid workflow ID"})
+		return
+	}
+
+	h.executionStats(c, []primitive.ObjectID{workflowID})
+}
+
+// GetProjectStats summarizes the executions of all workflows in a project
+func (h *ExecutionHandler) GetProjectStats(c *gin.Context) {
+	workflowIDs, ok := h.projectWorkflowIDs(c)
+	if !ok {
+		return
+	}
+
+	h.executionStats(c, workflowIDs)
+}
+
+// maxStatsBuckets limits the number of intervals a stats request may span
+const maxStatsBuckets = 1000
+
+// statsFilter reads the time range, interval and limit of a stats request.
+// The range defaults to the last 7 days, and the interval to an hour for
+// ranges up to 2 days and to a day otherwise.
+func statsFilter(c *gin.Context) (repository.ExecutionStatsFilter, error) {
+	filter := repository.ExecutionStatsFilter{
+		To:    time.Now().UTC(),
+		Limit: 10,
+	}
+
+	if v := c.Query("to"); v != "" {
+		t, err := parseTime("to", v)
+		if err != nil {
+			return filter, err
+		}
+		filter.To = t
+	}
+	if v := c.Query("interval"); v != "" {
+		interval, err := parseInterval(v)
+		if err != nil {
+			return filter, err
+		}
+		filter.Interval = interval
+	}
+	if v := c.Query("from"); v != "" {
+		t, err := parseTime("from", v)
+		if err != nil {
+			return filter, err
+		}
+		filter.From = t
+	} else {
+		filter.From = filter.To.Add(-7 * 24 * time.Hour)
+	}
+	if !filter.From.Before(filter.To) {
+		return filter, fmt.Errorf("from must be before to")
+	}
+
+	if filter.Interval == 0 {
+		filter.Interval = 24 * time.Hour
+		if filter.To.Sub(filter.From) <= 48*time.Hour {
+			filter.Interval = time.Hour
+		}
+	}
+	// Align the default range on whole intervals
+	if c.Query("from") == "" {
+		filter.From = filter.From.Truncate(filter.Interval)
+	}
+	if filter.To.Sub(filter.From)/filter.Interval >= maxStatsBuckets {
+		return filter, fmt.Errorf("the range spans more than %d intervals, use a longer interval", maxStatsBuckets)
+	}
 
-	// Get executions for all these workflows
-	executions, total, err := h.repo.GetByWorkflowIDs(c.Request.Context(), workflowIDs, page, pageSize)
+	if l := c.Query("limit"); l != "" {
+		if val, err := strconv.Atoi(l); err == nil && val > 0 && val <= 50 {
+			filter.Limit = val
+		}
+	}
+	return filter, nil
+}
+
+// parseInterval parses a bucket interval given as a Go duration such as 15m
+// or 6h, or as a number of days such as 1d or 7d
+func parseInterval(value string) (time.Duration, error) {
+	var interval time.Duration
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil {
+			interval = time.Duration(n) * 24 * time.Hour
+		}
+	} else if d, err := time.ParseDuration(value); err == nil {
+		interval = d
+	}
+	if interval < time.Minute {
+		return 0, fmt.Errorf("interval must be a duration of at least 1m, such as 15m, 1h or 1d")
+	}
+	return interval, nil
+}
+
+// executionStats responds with the stats of the executions of the given
+// workflows
+func (h *ExecutionHandler) executionStats(c *gin.Context, workflowIDs []primitive.ObjectID) {
+	filter, err := statsFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter.WorkflowIDs = workflowIDs
+
+	stats, err := h.repo.Stats(c.Request.Context(), filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	stats.Interval = formatInterval(filter.Interval)
 
-	c.JSON(http.StatusOK, gin.H{
-		"data":       executions,
-		"total":      total,
-		"page":       page,
-		"pageSize":   pageSize,
-		"totalPages": (total + int64(pageSize) - 1) / int64(pageSize),
-	})
+	c.JSON(http.StatusOK, stats)
+}
+
+// formatInterval formats an interval the way parseInterval reads it
+func formatInterval(interval time.Duration) string {
+	if day := 24 * time.Hour; interval%day == 0 {
+		return strconv.Itoa(int(interval/day)) + "d"
+	}
+	s := interval.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
 }
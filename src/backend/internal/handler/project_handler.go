@@ -101,6 +101,15 @@ func (h *ProjectHandler) Create(c *gin.Context) {
 		}
 	}
 
+	if err := domain.ValidateEnvironments(project.Environments, project.DefaultEnvironment); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := project.Retention.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Set default status
 	if project.Status == "" {
 		project.Status = domain.ProjectStatusDraft
@@ -158,6 +167,11 @@ func (h *ProjectHandler) Update(c *gin.Context) {
 		return
 	}
 
+	if err := project.Retention.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	if err := h.repo.Update(c.Request.Context(), id, &project); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update project"})
 		return
@@ -277,6 +291,56 @@ func (h *ProjectHandler) ToggleLock(c *gin.Context) {
 	c.JSON(http.StatusOK, updated)
 }
 
+// UpdateEnvironments godoc
+// @Summary Replace the environments of a project
+// @Description Set the environments (variables and credential bindings) and the default environment
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param body body domain.UpdateEnvironmentsRequest true "Environments"
+// @Success 200 {object} domain.Project
+// @Router /projects/{id}/environments [put]
+func (h *ProjectHandler) UpdateEnvironments(c *gin.Context) {
+	id := c.Param("id")
+
+	var req domain.UpdateEnvironmentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := domain.ValidateEnvironments(req.Environments, req.DefaultEnvironment); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	existing, err := h.repo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return
+	}
+	if existing.IsLocked {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Project is locked. Unlock it first to make changes."})
+		return
+	}
+
+	if req.Environments == nil {
+		req.Environments = []domain.Environment{}
+	}
+	if err := h.repo.UpdateEnvironments(c.Request.Context(), id, req.Environments, req.DefaultEnvironment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update environments"})
+		return
+	}
+
+	updated, err := h.repo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch updated project"})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
 // AddWorkflow godoc
 // @Summary Add a workflow to a project
 // @Description Add a new workflow to an existing project
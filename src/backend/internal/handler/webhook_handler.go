@@ -6,14 +6,16 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/nodetl/nodetl/internal/executor"
+	"github.com/nodetl/nodetl/internal/tracing"
 )
 
 type WebhookHandler struct {
 	flowExecutor *executor.FlowExecutor
+	workerPool   *executor.WorkerPool
 }
 
-func NewWebhookHandler(flowExecutor *executor.FlowExecutor) *WebhookHandler {
-	return &WebhookHandler{flowExecutor: flowExecutor}
+func NewWebhookHandler(flowExecutor *executor.FlowExecutor, workerPool *executor.WorkerPool) *WebhookHandler {
+	return &WebhookHandler{flowExecutor: flowExecutor, workerPool: workerPool}
 }
 
 // HandleWebhook handles incoming webhook requests and triggers workflows
@@ -61,8 +63,11 @@ func (h *WebhookHandler) executeWebhook(c *gin.Context, fullPath string, version
 		"version": version,
 	}
 	
-	// Execute workflow
-	result, err := h.flowExecutor.ExecuteByEndpoint(c.Request.Context(), fullPath, input)
+	// Continue the caller's trace when it sends a traceparent header
+	ctx := tracing.Extract(c.Request.Context(), c.Request.Header)
+
+	// Resolve the workflow serving this endpoint
+	execReq, workflow, err := h.flowExecutor.EndpointRequest(ctx, fullPath, input)
 	if err != nil {
 		if strings.Contains(err.Error(), "no active workflow found") {
 			c.JSON(http.StatusNotFound, gin.H{"error": "workflow not found for this endpoint"})
@@ -71,6 +76,28 @@ func (h *WebhookHandler) executeWebhook(c *gin.Context, fullPath string, version
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+
+	// Queue the execution when the workflow or the caller asks for async mode
+	async := workflow.Settings != nil && workflow.Settings.AsyncExecution
+	if strings.Contains(c.GetHeader("Prefer"), "respond-async") {
+		async = true
+	}
+	if async {
+		result, err := h.workerPool.Submit(ctx, execReq)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusAccepted, acceptedResponse(result))
+		return
+	}
+
+	// Execute workflow
+	result, err := h.flowExecutor.Execute(ctx, execReq)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 	
 	// Check if output contains response configuration
 	if result.Output != nil {
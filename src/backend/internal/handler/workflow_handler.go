@@ -6,19 +6,24 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/nodetl/nodetl/internal/domain"
+	"github.com/nodetl/nodetl/internal/executor"
 	"github.com/nodetl/nodetl/internal/repository"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type WorkflowHandler struct {
-	repo        repository.WorkflowRepository
-	projectRepo *repository.ProjectRepository
+	repo         repository.WorkflowRepository
+	projectRepo  *repository.ProjectRepository
+	flowExecutor *executor.FlowExecutor
+	scheduler    *executor.Scheduler // nil when schedules run on another instance
 }
 
-func NewWorkflowHandler(repo repository.WorkflowRepository, projectRepo *repository.ProjectRepository) *WorkflowHandler {
+func NewWorkflowHandler(repo repository.WorkflowRepository, projectRepo *repository.ProjectRepository, flowExecutor *executor.FlowExecutor, scheduler *executor.Scheduler) *WorkflowHandler {
 	return &WorkflowHandler{
-		repo:        repo,
-		projectRepo: projectRepo,
+		repo:         repo,
+		projectRepo:  projectRepo,
+		flowExecutor: flowExecutor,
+		scheduler:    scheduler,
 	}
 }
 
@@ -258,6 +263,7 @@ func (h *WorkflowHandler) UpdateWorkflow(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	h.syncSchedule(&workflow)
 
 	c.JSON(http.StatusOK, workflow)
 }
@@ -324,6 +330,15 @@ func (h *WorkflowHandler) ActivateWorkflow(c *gin.Context) {
 		return
 	}
 
+	// Refuse to activate workflows that would fail at request time
+	if issues := h.flowExecutor.Validate(c.Request.Context(), workflow); len(issues) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":  "workflow is invalid",
+			"issues": issues,
+		})
+		return
+	}
+
 	workflow.Status = domain.WorkflowStatusActive
 
 	// Generate endpoint if not exists
@@ -339,10 +354,36 @@ func (h *WorkflowHandler) ActivateWorkflow(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	h.syncSchedule(workflow)
 
 	c.JSON(http.StatusOK, workflow)
 }
 
+// ValidateWorkflow checks a workflow without changing it
+func (h *WorkflowHandler) ValidateWorkflow(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid workflow ID"})
+		return
+	}
+
+	workflow, err := h.repo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if workflow == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "workflow not found"})
+		return
+	}
+
+	issues := h.flowExecutor.Validate(c.Request.Context(), workflow)
+	c.JSON(http.StatusOK, gin.H{
+		"valid":  len(issues) == 0,
+		"issues": issues,
+	})
+}
+
 // DeactivateWorkflow deactivates a workflow
 func (h *WorkflowHandler) DeactivateWorkflow(c *gin.Context) {
 	id, err := primitive.ObjectIDFromHex(c.Param("id"))
@@ -367,6 +408,7 @@ func (h *WorkflowHandler) DeactivateWorkflow(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	h.syncSchedule(workflow)
 
 	c.JSON(http.StatusOK, workflow)
 }
@@ -410,9 +452,23 @@ func (h *WorkflowHandler) PatchWorkflow(c *gin.Context) {
 		return
 	}
 
+	// Pick up schedule changes made to an active workflow
+	if h.scheduler != nil {
+		if workflow, err := h.repo.GetByID(c.Request.Context(), id); err == nil && workflow != nil {
+			h.scheduler.Sync(workflow)
+		}
+	}
+
 	// Return minimal response for auto-save
 	c.JSON(http.StatusOK, gin.H{
 		"id":      id.Hex(),
 		"success": true,
 	})
 }
+
+// syncSchedule re-registers the schedule triggers of a saved workflow
+func (h *WorkflowHandler) syncSchedule(workflow *domain.Workflow) {
+	if h.scheduler != nil {
+		h.scheduler.Sync(workflow)
+	}
+}
@@ -0,0 +1,150 @@
+// Package metrics exposes the Prometheus metrics of the server: API requests,
+// workflow executions, node runs and the outbound requests of HTTP nodes.
+// Metrics are registered with the default registry, which also collects the
+// Go runtime and process metrics.
+package metrics
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "nodetl"
+
+// durationBuckets cover requests and executions from a few milliseconds to
+// several minutes
+var durationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "API requests by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "API request latency by method and route.",
+		Buckets:   durationBuckets,
+	}, []string{"method", "route"})
+
+	executions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "executions_total",
+		Help:      "Finished workflow executions by workflow and status.",
+	}, []string{"workflow_id", "workflow", "status"})
+
+	executionDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "execution_duration_seconds",
+		Help:      "Duration of finished workflow executions by workflow and status.",
+		Buckets:   durationBuckets,
+	}, []string{"workflow_id", "workflow", "status"})
+
+	executionsInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "executions_in_flight",
+		Help:      "Workflow executions running on this instance by workflow.",
+	}, []string{"workflow_id", "workflow"})
+
+	nodeDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "node_duration_seconds",
+		Help:      "Duration of node runs by node type and status.",
+		Buckets:   durationBuckets,
+	}, []string{"node_type", "status"})
+
+	outboundRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "outbound_requests_total",
+		Help:      "Requests sent by HTTP nodes by host, method and status code; status is \"error\" when no response was received.",
+	}, []string{"host", "method", "status"})
+
+	outboundRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "outbound_request_duration_seconds",
+		Help:      "Latency of requests sent by HTTP nodes by host and method.",
+		Buckets:   durationBuckets,
+	}, []string{"host", "method"})
+)
+
+// ObserveHTTPRequest records an API request. route is the route pattern,
+// such as /api/v1/workflows/:id, so that IDs do not create new series.
+func ObserveHTTPRequest(method, route string, status int, latency time.Duration) {
+	if route == "" {
+		route = "unmatched"
+	}
+	httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	httpRequestDuration.WithLabelValues(method, route).Observe(latency.Seconds())
+}
+
+// ObserveExecution records a finished workflow execution
+func ObserveExecution(workflowID, workflowName, status string, duration time.Duration) {
+	executions.WithLabelValues(workflowID, workflowName, status).Inc()
+	executionDuration.WithLabelValues(workflowID, workflowName, status).Observe(duration.Seconds())
+}
+
+// ExecutionStarted counts a running execution until the returned function is
+// called
+func ExecutionStarted(workflowID, workflowName string) (done func()) {
+	gauge := executionsInFlight.WithLabelValues(workflowID, workflowName)
+	gauge.Inc()
+	return gauge.Dec
+}
+
+// ObserveNode records a node run
+func ObserveNode(nodeType, status string, duration time.Duration) {
+	nodeDuration.WithLabelValues(nodeType, status).Observe(duration.Seconds())
+}
+
+// Transport records the requests sent through the next round tripper, or
+// through http.DefaultTransport if it is nil
+func Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &transport{next: next}
+}
+
+type transport struct {
+	next http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+
+	host := req.URL.Hostname()
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(resp.StatusCode)
+	}
+	outboundRequests.WithLabelValues(host, req.Method, status).Inc()
+	outboundRequestDuration.WithLabelValues(host, req.Method).Observe(time.Since(start).Seconds())
+	return resp, err
+}
+
+// Handler serves the metrics in the Prometheus text format. With a token,
+// scrapers must send it as a bearer token.
+func Handler(token string) http.Handler {
+	metrics := promhttp.Handler()
+	if token == "" {
+		return metrics
+	}
+	expected := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		metrics.ServeHTTP(w, r)
+	})
+}
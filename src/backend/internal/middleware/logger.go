@@ -4,10 +4,12 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/nodetl/nodetl/internal/metrics"
 	"github.com/nodetl/nodetl/pkg/logger"
 )
 
-// Logger middleware logs request details
+// Logger middleware logs request details and records them in the request
+// metrics
 func Logger() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
@@ -18,6 +20,7 @@ func Logger() gin.HandlerFunc {
 		
 		end := time.Now()
 		latency := end.Sub(start)
+		metrics.ObserveHTTPRequest(c.Request.Method, c.FullPath(), c.Writer.Status(), latency)
 		
 		if len(c.Errors) > 0 {
 			for _, e := range c.Errors.Errors() {
@@ -4,9 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/nodetl/nodetl/internal/domain"
+	"github.com/nodetl/nodetl/internal/expression"
 )
 
 // CodeNode executes custom code/expressions
@@ -32,9 +34,8 @@ func (n *CodeNode) Execute(ctx context.Context, execCtx *ExecutionContext, nodeD
 		Timestamp: time.Now(),
 	})
 	
-	// For safety, we use a simple expression evaluator instead of full JS execution
-	// In production, you might want to use a sandboxed JS runtime like otto or goja
-	output, err := evaluateExpression(nodeData.Code, execCtx.Input)
+	// For safety, code is an expression rather than a full JS program
+	output, err := evaluateExpression(nodeData.Code, expressionScope(execCtx))
 	if err != nil {
 		logs = append(logs, domain.LogEntry{
 			Level:     "error",
@@ -61,30 +62,24 @@ func (n *CodeNode) Execute(ctx context.Context, execCtx *ExecutionContext, nodeD
 	}, nil
 }
 
-// evaluateExpression is a simple expression evaluator
-// Supports basic JSON transformations and field access
-func evaluateExpression(code string, input map[string]any) (any, error) {
-	// If code is a JSON template, try to parse it
-	if len(code) > 0 && (code[0] == '{' || code[0] == '[') {
+// evaluateExpression evaluates the code of a code node. JSON templates
+// such as {"total": {{ sum(items[*].price) }}} build an object or array;
+// anything else is a single expression.
+func evaluateExpression(code string, scope *expression.Scope) (any, error) {
+	trimmed := strings.TrimSpace(code)
+	if strings.HasPrefix(trimmed, "{") || (strings.HasPrefix(trimmed, "[") && expression.IsTemplate(trimmed)) {
+		processed, err := expression.RenderJSON(trimmed, scope)
+		if err != nil {
+			return nil, err
+		}
 		var result any
-		// Replace variables in the template
-		processed := replaceVariables(code, input)
 		if err := json.Unmarshal([]byte(processed), &result); err != nil {
 			return nil, fmt.Errorf("invalid JSON expression: %v", err)
 		}
 		return result, nil
 	}
 	
-	// If code is a field path, return the value
-	if value := getNestedValue(input, code); value != nil {
-		return value, nil
-	}
-	
-	// Otherwise, return the input with code as a marker
-	return map[string]any{
-		"expression": code,
-		"data":       input,
-	}, nil
+	return expression.Eval(trimmed, scope)
 }
 
 // DelayNode pauses execution for a specified duration
@@ -3,12 +3,12 @@ package node
 import (
 	"context"
 	"fmt"
-	"reflect"
 	"regexp"
 	"strings"
 	"time"
 
 	"github.com/nodetl/nodetl/internal/domain"
+	"github.com/nodetl/nodetl/internal/expression"
 )
 
 // ConditionNode branches workflow based on conditions
@@ -22,26 +22,52 @@ func (n *ConditionNode) Validate(nodeData domain.NodeData) error {
 	if len(nodeData.Conditions) == 0 {
 		return fmt.Errorf("condition node requires at least one condition")
 	}
+	for _, condition := range nodeData.Conditions {
+		if condition.Operator == "expression" {
+			continue
+		}
+		if err := validateField(condition.Field); err != nil {
+			return fmt.Errorf("condition field: %w", err)
+		}
+	}
 	return nil
 }
 
 func (n *ConditionNode) Execute(ctx context.Context, execCtx *ExecutionContext, nodeData domain.NodeData) (*ExecutionResult, error) {
 	input := execCtx.Input
 	logs := []domain.LogEntry{}
+	scope := expressionScope(execCtx)
 	
 	for _, condition := range nodeData.Conditions {
-		fieldValue := getNestedValue(input, condition.Field)
+		var fieldValue any
+		var err error
+		if condition.Operator == "expression" && !expression.IsTemplate(condition.Field) {
+			fieldValue, err = expression.Eval(condition.Field, scope)
+		} else {
+			fieldValue, err = resolveField(scope, input, condition.Field)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("condition %s: %w", condition.Field, err)
+		}
+		
+		// The expected value may itself be a template, e.g. "{{ $vars.threshold }}"
+		expected := condition.Value
+		if s, ok := expected.(string); ok && expression.IsTemplate(s) {
+			if expected, err = expression.Resolve(s, scope); err != nil {
+				return nil, fmt.Errorf("condition %s: %w", condition.Field, err)
+			}
+		}
 		
-		result := evaluateCondition(fieldValue, condition.Operator, condition.Value)
+		result := evaluateCondition(fieldValue, condition.Operator, expected)
 		
 		logs = append(logs, domain.LogEntry{
 			Level:     "debug",
-			Message:   fmt.Sprintf("Evaluating condition: %s %s %v = %v", condition.Field, condition.Operator, condition.Value, result),
+			Message:   fmt.Sprintf("Evaluating condition: %s %s %v = %v", condition.Field, condition.Operator, expected, result),
 			Timestamp: time.Now(),
 			Data: map[string]any{
 				"field":      condition.Field,
 				"operator":   condition.Operator,
-				"expected":   condition.Value,
+				"expected":   expected,
 				"actual":     fieldValue,
 				"result":     result,
 			},
@@ -64,25 +90,36 @@ func (n *ConditionNode) Execute(ctx context.Context, execCtx *ExecutionContext,
 	}, nil
 }
 
+// evaluateCondition applies an operator to the field value. Equality and
+// ordering follow the expression language, so 5 equals 5.0 and dates compare
+// chronologically. The expression operator tests the truthiness of the
+// field, which is then a full expression such as total > 100.
 func evaluateCondition(fieldValue any, operator string, expectedValue any) bool {
 	switch operator {
+	case "expression":
+		return expression.Truthy(fieldValue)
+		
 	case "eq", "==", "equals":
-		return reflect.DeepEqual(fieldValue, expectedValue)
+		return expression.Equal(fieldValue, expectedValue)
 		
 	case "neq", "!=", "notEquals":
-		return !reflect.DeepEqual(fieldValue, expectedValue)
+		return !expression.Equal(fieldValue, expectedValue)
 		
 	case "gt", ">":
-		return compareNumbers(fieldValue, expectedValue) > 0
+		cmp, ok := expression.Compare(fieldValue, expectedValue)
+		return ok && cmp > 0
 		
 	case "gte", ">=":
-		return compareNumbers(fieldValue, expectedValue) >= 0
+		cmp, ok := expression.Compare(fieldValue, expectedValue)
+		return ok && cmp >= 0
 		
 	case "lt", "<":
-		return compareNumbers(fieldValue, expectedValue) < 0
+		cmp, ok := expression.Compare(fieldValue, expectedValue)
+		return ok && cmp < 0
 		
 	case "lte", "<=":
-		return compareNumbers(fieldValue, expectedValue) <= 0
+		cmp, ok := expression.Compare(fieldValue, expectedValue)
+		return ok && cmp <= 0
 		
 	case "contains":
 		if s, ok := fieldValue.(string); ok {
@@ -126,7 +163,7 @@ func evaluateCondition(fieldValue any, operator string, expectedValue any) bool
 	case "in":
 		if arr, ok := expectedValue.([]any); ok {
 			for _, item := range arr {
-				if reflect.DeepEqual(fieldValue, item) {
+				if expression.Equal(fieldValue, item) {
 					return true
 				}
 			}
@@ -137,32 +174,3 @@ func evaluateCondition(fieldValue any, operator string, expectedValue any) bool
 		return false
 	}
 }
-
-func compareNumbers(a, b any) int {
-	aFloat := toFloat64(a)
-	bFloat := toFloat64(b)
-	
-	if aFloat < bFloat {
-		return -1
-	} else if aFloat > bFloat {
-		return 1
-	}
-	return 0
-}
-
-func toFloat64(v any) float64 {
-	switch n := v.(type) {
-	case float64:
-		return n
-	case float32:
-		return float64(n)
-	case int:
-		return float64(n)
-	case int32:
-		return float64(n)
-	case int64:
-		return float64(n)
-	default:
-		return 0
-	}
-}
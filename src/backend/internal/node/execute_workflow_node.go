@@ -0,0 +1,91 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nodetl/nodetl/internal/domain"
+)
+
+// ExecuteWorkflowNode runs another workflow with the (optionally mapped) input
+// and emits the child's output, or just its execution ID when fire and forget
+type ExecuteWorkflowNode struct{}
+
+func (n *ExecuteWorkflowNode) GetType() string {
+	return domain.NodeTypeExecuteWorkflow
+}
+
+func (n *ExecuteWorkflowNode) Validate(nodeData domain.NodeData) error {
+	if nodeData.SubWorkflowID == "" && nodeData.SubWorkflowPath == "" {
+		return fmt.Errorf("execute workflow node requires a workflow ID or endpoint path")
+	}
+	return nil
+}
+
+func (n *ExecuteWorkflowNode) Execute(ctx context.Context, execCtx *ExecutionContext, nodeData domain.NodeData) (*ExecutionResult, error) {
+	if execCtx.Workflows == nil {
+		return nil, fmt.Errorf("sub-workflows are not supported in this context")
+	}
+
+	input := execCtx.Input
+	logs := []domain.LogEntry{}
+	if len(nodeData.MappingRules) > 0 {
+		var mappingLogs []domain.LogEntry
+		input, mappingLogs = applyMappingRules(execCtx.Input, nodeData.MappingRules, expressionScope(execCtx))
+		logs = append(logs, mappingLogs...)
+	}
+
+	target := nodeData.SubWorkflowID
+	if target == "" {
+		target = nodeData.SubWorkflowPath
+	}
+	logs = append(logs, domain.LogEntry{
+		Level:     "info",
+		Message:   fmt.Sprintf("Executing workflow %s", target),
+		Timestamp: time.Now(),
+	})
+
+	result, err := execCtx.Workflows.RunWorkflow(ctx, WorkflowRunRequest{
+		WorkflowID:   nodeData.SubWorkflowID,
+		EndpointPath: nodeData.SubWorkflowPath,
+		Input:        input,
+		Wait:         !nodeData.SubWorkflowAsync,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logs = append(logs, domain.LogEntry{
+		Level:     "info",
+		Message:   fmt.Sprintf("Child execution %s %s", result.ExecutionID, result.Status),
+		Timestamp: time.Now(),
+		Data:      map[string]any{"executionId": result.ExecutionID},
+	})
+
+	if nodeData.SubWorkflowAsync {
+		return &ExecutionResult{
+			Output: map[string]any{
+				"executionId": result.ExecutionID,
+				"status":      result.Status,
+			},
+			Logs:     logs,
+			NextPort: "output",
+		}, nil
+	}
+
+	if result.Error != "" {
+		return &ExecutionResult{
+			Error:    fmt.Errorf("child execution %s failed: %s", result.ExecutionID, result.Error),
+			Logs:     logs,
+			Output:   map[string]any{"executionId": result.ExecutionID, "status": result.Status},
+			NextPort: "error",
+		}, nil
+	}
+
+	return &ExecutionResult{
+		Output:   result.Output,
+		Logs:     logs,
+		NextPort: "output",
+	}, nil
+}
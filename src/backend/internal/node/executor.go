@@ -2,6 +2,9 @@ package node
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
 
 	"github.com/nodetl/nodetl/internal/domain"
 )
@@ -12,23 +15,133 @@ type ExecutionContext struct {
 	ExecutionID     string
 	NodeID          string
 	TraceID         string         // Unique trace ID for request tracking
+	Environment     string         // Project environment the execution runs in, if any
 	Input           map[string]any // Current input (output from previous node)
 	TriggerInput    map[string]any // Original input from trigger (webhook request)
 	PreviousInput   map[string]any // Input of previous node (before it processed)
-	Variables       map[string]any // Workflow-level variables
-	PreviousData    map[string]any // Data from previous nodes
+	Variables       map[string]any // Workflow variables, overridden by those of the environment
+	PreviousData    map[string]any // Outputs of the nodes that already completed, keyed by node ID
 	Metadata        map[string]any
-	Error           *ExecutionError // Error from previous nodes
+	Error           *ExecutionError    // Error from previous nodes
+	Branches        BranchRunner       // Runs the subgraph connected to one of the node's output ports
+	Workflows       WorkflowRunner     // Runs other workflows as children of this execution
+	Credentials     CredentialResolver // Applies stored credentials to outgoing requests
+}
+
+// BranchRunner executes the part of the workflow connected to an output port
+// of the current node and returns the output of that branch. Nodes such as
+// Loop use it to run their body once per iteration.
+type BranchRunner interface {
+	RunBranch(ctx context.Context, port string, input map[string]any) (map[string]any, error)
+}
+
+// WorkflowRunner starts another workflow as a child of the current execution
+type WorkflowRunner interface {
+	RunWorkflow(ctx context.Context, req WorkflowRunRequest) (*WorkflowRunResult, error)
+}
+
+// CredentialResolver authorizes an outgoing request with a stored credential,
+// e.g. by setting its Authorization header. Secret values stay out of node
+// settings and outputs.
+type CredentialResolver interface {
+	Authorize(ctx context.Context, credentialID string, req *http.Request) error
+}
+
+// WorkflowRunRequest identifies the child workflow by ID or, failing that, by
+// endpoint path. With Wait unset the child runs in the background.
+type WorkflowRunRequest struct {
+	WorkflowID   string
+	EndpointPath string
+	Input        map[string]any
+	Wait         bool
+}
+
+// WorkflowRunResult is the outcome of a child workflow. Output and Error are
+// only set once the child has finished.
+type WorkflowRunResult struct {
+	ExecutionID string
+	Status      string
+	Output      map[string]any
+	Error       string
 }
 
 // ExecutionError represents an error during execution
 type ExecutionError struct {
-	Type       string `json:"type"`       // validation, not_found, unauthorized, forbidden, internal
+	Type       string `json:"type"`       // validation_error, not_found, unauthorized, forbidden, timeout, internal
 	Message    string `json:"message"`
 	StatusCode int    `json:"statusCode"`
+	NodeID     string `json:"nodeId,omitempty"` // Node that failed
 	Details    any    `json:"details,omitempty"`
 }
 
+// Execution error types
+const (
+	ErrorTypeValidation   = "validation_error"
+	ErrorTypeNotFound     = "not_found"
+	ErrorTypeUnauthorized = "unauthorized"
+	ErrorTypeForbidden    = "forbidden"
+	ErrorTypeTimeout      = "timeout"
+	ErrorTypeInternal     = "internal"
+)
+
+// Error implements the error interface so nodes can return typed errors
+func (e *ExecutionError) Error() string {
+	return e.Message
+}
+
+// ToMap returns the error in the shape exposed to templates and downstream nodes
+func (e *ExecutionError) ToMap() map[string]any {
+	m := map[string]any{
+		"type":       e.Type,
+		"message":    e.Message,
+		"statusCode": e.StatusCode,
+	}
+	if e.NodeID != "" {
+		m["nodeId"] = e.NodeID
+	}
+	if e.Details != nil {
+		m["details"] = e.Details
+	}
+	return m
+}
+
+// NewExecutionError converts an error returned by a node into a typed error.
+// Typed errors are returned as they are; anything else is internal.
+func NewExecutionError(err error) *ExecutionError {
+	var execErr *ExecutionError
+	if errors.As(err, &execErr) {
+		return execErr
+	}
+	return &ExecutionError{
+		Type:       ErrorTypeInternal,
+		Message:    err.Error(),
+		StatusCode: http.StatusInternalServerError,
+	}
+}
+
+// NewStatusError creates a typed error for an HTTP error status reported by a node
+func NewStatusError(statusCode int, details any) *ExecutionError {
+	errType := ErrorTypeInternal
+	switch statusCode {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		errType = ErrorTypeValidation
+	case http.StatusUnauthorized:
+		errType = ErrorTypeUnauthorized
+	case http.StatusForbidden:
+		errType = ErrorTypeForbidden
+	case http.StatusNotFound:
+		errType = ErrorTypeNotFound
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		errType = ErrorTypeTimeout
+	}
+	return &ExecutionError{
+		Type:       errType,
+		Message:    fmt.Sprintf("received HTTP status %d", statusCode),
+		StatusCode: statusCode,
+		Details:    details,
+	}
+}
+
 // ExecutionResult is the result of a node execution
 type ExecutionResult struct {
 	Output   map[string]any
@@ -39,7 +152,9 @@ type ExecutionResult struct {
 
 // NodeExecutor is the interface that all node types must implement
 type NodeExecutor interface {
-	// Execute runs the node logic
+	// Execute runs the node logic. It must return once ctx is done, as the
+	// node timeout and cancellation are signalled through ctx; a node that
+	// keeps running is still reported as timed out, but its work continues.
 	Execute(ctx context.Context, execCtx *ExecutionContext, nodeData domain.NodeData) (*ExecutionResult, error)
 	
 	// Validate validates node configuration before execution
@@ -93,4 +208,5 @@ func (r *Registry) registerBuiltIn() {
 	r.Register(&LoopNode{})
 	r.Register(&CodeNode{})
 	r.Register(&DelayNode{})
+	r.Register(&ExecuteWorkflowNode{})
 }
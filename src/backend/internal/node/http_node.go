@@ -4,15 +4,23 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	neturl "net/url"
 	"strings"
 	"time"
 
 	"github.com/nodetl/nodetl/internal/domain"
+	"github.com/nodetl/nodetl/internal/expression"
+	"github.com/nodetl/nodetl/internal/metrics"
+	"github.com/nodetl/nodetl/internal/tracing"
 )
 
+// defaultHTTPTimeout applies to requests from nodes without a timeout
+const defaultHTTPTimeout = 30 * time.Second
+
 // HTTPNode makes HTTP requests to external APIs
 type HTTPNode struct{}
 
@@ -33,13 +41,21 @@ func (n *HTTPNode) Validate(nodeData domain.NodeData) error {
 func (n *HTTPNode) Execute(ctx context.Context, execCtx *ExecutionContext, nodeData domain.NodeData) (*ExecutionResult, error) {
 	logs := []domain.LogEntry{}
 	
-	// Prepare URL (replace variables)
-	url := replaceVariables(nodeData.HTTPURL, execCtx.Input)
+	scope := expressionScope(execCtx)
+
+	// Prepare URL (resolve expressions)
+	url, err := expression.Render(nodeData.HTTPURL, scope)
+	if err != nil {
+		return expressionError(err), nil
+	}
 	
 	// Prepare body
 	var bodyReader io.Reader
 	if nodeData.HTTPBody != "" {
-		body := replaceVariables(nodeData.HTTPBody, execCtx.Input)
+		body, err := renderBody(nodeData.HTTPBody, scope)
+		if err != nil {
+			return expressionError(err), nil
+		}
 		bodyReader = bytes.NewBufferString(body)
 	} else if nodeData.HTTPMethod != "GET" && nodeData.HTTPMethod != "DELETE" {
 		// Use input as JSON body
@@ -62,7 +78,30 @@ func (n *HTTPNode) Execute(ctx context.Context, execCtx *ExecutionContext, nodeD
 	// Set headers
 	req.Header.Set("Content-Type", "application/json")
 	for key, value := range nodeData.HTTPHeaders {
-		req.Header.Set(key, replaceVariables(value, execCtx.Input))
+		headerValue, err := expression.Render(value, scope)
+		if err != nil {
+			return expressionError(err), nil
+		}
+		req.Header.Set(key, headerValue)
+	}
+	
+	// Authorize with the stored credential, after the headers so it wins.
+	// Errors show the URL from before, without an API key added to the query.
+	requestURL := req.URL.Redacted()
+	if nodeData.CredentialID != "" {
+		if execCtx.Credentials == nil {
+			err = fmt.Errorf("credentials are not available")
+		} else {
+			err = execCtx.Credentials.Authorize(ctx, nodeData.CredentialID, req)
+		}
+		if err != nil {
+			err = fmt.Errorf("credential %s: %w", nodeData.CredentialID, err)
+			return &ExecutionResult{
+				Error:    err,
+				NextPort: "error",
+				Output:   map[string]any{"error": err.Error()},
+			}, nil
+		}
 	}
 	
 	logs = append(logs, domain.LogEntry{
@@ -72,9 +111,15 @@ func (n *HTTPNode) Execute(ctx context.Context, execCtx *ExecutionContext, nodeD
 	})
 	
 	// Execute request
-	client := &http.Client{Timeout: 30 * time.Second}
+	// A node timeout replaces the default so longer requests are allowed
+	timeout := defaultHTTPTimeout
+	if nodeData.TimeoutMs > 0 {
+		timeout = time.Duration(nodeData.TimeoutMs) * time.Millisecond
+	}
+	client := &http.Client{Timeout: timeout, Transport: tracing.Transport(metrics.Transport(nil))}
 	resp, err := client.Do(req)
 	if err != nil {
+		err = withRequestURL(err, requestURL)
 		logs = append(logs, domain.LogEntry{
 			Level:     "error",
 			Message:   fmt.Sprintf("Request failed: %v", err),
@@ -130,22 +175,35 @@ func (n *HTTPNode) Execute(ctx context.Context, execCtx *ExecutionContext, nodeD
 	}, nil
 }
 
-// replaceVariables replaces {{variable}} patterns with actual values
-func replaceVariables(template string, data map[string]any) string {
-	result := template
-	for key, value := range data {
-		placeholder := fmt.Sprintf("{{%s}}", key)
-		result = strings.ReplaceAll(result, placeholder, fmt.Sprintf("%v", value))
-		
-		// Also handle nested with dot notation
-		if m, ok := value.(map[string]any); ok {
-			for nestedKey, nestedValue := range m {
-				nestedPlaceholder := fmt.Sprintf("{{%s.%s}}", key, nestedKey)
-				result = strings.ReplaceAll(result, nestedPlaceholder, fmt.Sprintf("%v", nestedValue))
-			}
-		}
+// renderBody fills the placeholders of a request body. In JSON bodies quoted
+// placeholders stay strings and are escaped, while unquoted ones insert the
+// JSON value; other bodies get plain text.
+func renderBody(body string, scope *expression.Scope) (string, error) {
+	trimmed := strings.TrimSpace(body)
+	if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		return expression.RenderJSON(body, scope)
 	}
-	return result
+	return expression.Render(body, scope)
+}
+
+// expressionError is the result of a node whose settings hold an invalid
+// expression
+func expressionError(err error) *ExecutionResult {
+	return &ExecutionResult{
+		Error:    err,
+		NextPort: "error",
+		Output:   map[string]any{"error": err.Error()},
+	}
+}
+
+// withRequestURL replaces the URL in the error of a failed request, which
+// includes the query the credential may have added
+func withRequestURL(err error, requestURL string) error {
+	var urlErr *neturl.Error
+	if errors.As(err, &urlErr) {
+		return &neturl.Error{Op: urlErr.Op, URL: requestURL, Err: urlErr.Err}
+	}
+	return err
 }
 
 func headerToMap(header http.Header) map[string]string {
@@ -0,0 +1,82 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/nodetl/nodetl/internal/domain"
+)
+
+func TestRenderBodyKeepsQuotedPlaceholdersAsStrings(t *testing.T) {
+	execCtx := &ExecutionContext{Input: map[string]any{
+		"id":    float64(42),
+		"price": 9.5,
+		"name":  `Ada "A"`,
+		"tags":  []any{"a", "b"},
+	}}
+
+	tests := []struct {
+		body string
+		want string
+	}{
+		{`{"id": "{{id}}"}`, `{"id": "42"}`},
+		{`{"id": "{{ $input.id }}", "price": "{{price}}"}`, `{"id": "42", "price": "9.5"}`},
+		{`{"name": "{{name}}"}`, `{"name": "Ada \"A\""}`},
+		{`{"id": {{id}}, "tags": {{tags}}}`, `{"id": 42, "tags": ["a","b"]}`},
+		{`id={{id}}`, `id=42`},
+	}
+	for _, tt := range tests {
+		got, err := renderBody(tt.body, expressionScope(execCtx))
+		if err != nil {
+			t.Fatalf("renderBody(%s) error = %v", tt.body, err)
+		}
+		if got != tt.want {
+			t.Errorf("renderBody(%s) = %s, want %s", tt.body, got, tt.want)
+		}
+	}
+}
+
+// queryKey adds an API key to the query, like an api_key credential with
+// "in": "query"
+type queryKey struct{}
+
+func (queryKey) Authorize(ctx context.Context, credentialID string, req *http.Request) error {
+	query := req.URL.Query()
+	query.Set("api_key", "s3cret")
+	req.URL.RawQuery = query.Encode()
+	return nil
+}
+
+func TestHTTPNodeErrorsHideQueryCredential(t *testing.T) {
+	execCtx := &ExecutionContext{Input: map[string]any{}, Credentials: queryKey{}}
+	nodeData := domain.NodeData{
+		HTTPURL:      "http://127.0.0.1:1/orders?page=2",
+		HTTPMethod:   "GET",
+		CredentialID: "c-1",
+		TimeoutMs:    2000,
+	}
+
+	result, err := (&HTTPNode{}).Execute(context.Background(), execCtx, nodeData)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result.Error == nil {
+		t.Fatal("Execute() succeeded against an unreachable host")
+	}
+
+	texts := []string{result.Error.Error(), fmt.Sprint(result.Output)}
+	for _, entry := range result.Logs {
+		texts = append(texts, entry.Message)
+	}
+	for _, text := range texts {
+		if strings.Contains(text, "s3cret") {
+			t.Errorf("credential leaked in %q", text)
+		}
+	}
+	if !strings.Contains(result.Error.Error(), "/orders?page=2") {
+		t.Errorf("error %q does not name the request URL", result.Error)
+	}
+}
@@ -3,12 +3,23 @@ package node
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/nodetl/nodetl/internal/domain"
+	"github.com/nodetl/nodetl/internal/expression"
 )
 
-// LoopNode iterates over arrays or conditions
+const (
+	// defaultLoopMaxIterations caps loops that don't set loopMaxIterations
+	defaultLoopMaxIterations = 1000
+
+	// loopItemPort is the output port whose subgraph runs once per iteration
+	loopItemPort = "item"
+)
+
+// LoopNode iterates over arrays or conditions, running the subgraph connected
+// to its "item" port once per iteration and emitting all results on "done"
 type LoopNode struct{}
 
 func (n *LoopNode) GetType() string {
@@ -16,8 +27,32 @@ func (n *LoopNode) GetType() string {
 }
 
 func (n *LoopNode) Validate(nodeData domain.NodeData) error {
-	if nodeData.LoopType == "" {
+	switch nodeData.LoopType {
+	case "":
 		return fmt.Errorf("loop node requires a loop type")
+	case "forEach":
+		if nodeData.LoopArrayPath == "" {
+			return fmt.Errorf("forEach loop requires an array path")
+		}
+		if err := validateField(nodeData.LoopArrayPath); err != nil {
+			return fmt.Errorf("loop array path: %w", err)
+		}
+	case "while":
+		if nodeData.LoopCondition == "" {
+			return fmt.Errorf("while loop requires a loop condition")
+		}
+	case "for":
+		if nodeData.LoopCount <= 0 {
+			return fmt.Errorf("for loop requires a positive loop count")
+		}
+	default:
+		return fmt.Errorf("unknown loop type: %s", nodeData.LoopType)
+	}
+	if nodeData.LoopConcurrency < 0 {
+		return fmt.Errorf("loop concurrency cannot be negative")
+	}
+	if nodeData.LoopMaxIterations < 0 {
+		return fmt.Errorf("loop max iterations cannot be negative")
 	}
 	return nil
 }
@@ -25,22 +60,25 @@ func (n *LoopNode) Validate(nodeData domain.NodeData) error {
 func (n *LoopNode) Execute(ctx context.Context, execCtx *ExecutionContext, nodeData domain.NodeData) (*ExecutionResult, error) {
 	input := execCtx.Input
 	logs := []domain.LogEntry{}
-	
+
 	switch nodeData.LoopType {
 	case "forEach":
-		return n.executeForEach(ctx, input, nodeData, logs)
+		return n.executeForEach(ctx, execCtx, input, nodeData, logs)
 	case "while":
-		return n.executeWhile(ctx, input, nodeData, logs)
+		return n.executeWhile(ctx, execCtx, input, nodeData, logs)
 	case "for":
-		return n.executeFor(ctx, input, nodeData, logs)
+		return n.executeFor(ctx, execCtx, input, nodeData, logs)
 	default:
 		return nil, fmt.Errorf("unknown loop type: %s", nodeData.LoopType)
 	}
 }
 
-func (n *LoopNode) executeForEach(ctx context.Context, input map[string]any, nodeData domain.NodeData, logs []domain.LogEntry) (*ExecutionResult, error) {
-	arrayValue := getNestedValue(input, nodeData.LoopArrayPath)
-	
+func (n *LoopNode) executeForEach(ctx context.Context, execCtx *ExecutionContext, input map[string]any, nodeData domain.NodeData, logs []domain.LogEntry) (*ExecutionResult, error) {
+	arrayValue, err := resolveField(expressionScope(execCtx), input, nodeData.LoopArrayPath)
+	if err != nil {
+		return nil, err
+	}
+
 	array, ok := arrayValue.([]any)
 	if !ok {
 		if arrayValue == nil {
@@ -49,18 +87,28 @@ func (n *LoopNode) executeForEach(ctx context.Context, input map[string]any, nod
 			return nil, fmt.Errorf("value at path %s is not an array", nodeData.LoopArrayPath)
 		}
 	}
-	
+
+	if len(array) > maxIterations(nodeData) {
+		return nil, fmt.Errorf("array at path %s has %d items, exceeding the loop limit of %d", nodeData.LoopArrayPath, len(array), maxIterations(nodeData))
+	}
+
 	logs = append(logs, domain.LogEntry{
 		Level:     "info",
 		Message:   fmt.Sprintf("Starting forEach loop with %d items", len(array)),
 		Timestamp: time.Now(),
 	})
-	
-	// For now, we return the array items
-	// In a full implementation, this would trigger sub-executions for each item
+
+	results, err := n.runIterations(ctx, execCtx, len(array), nodeData.LoopConcurrency, func(i int) map[string]any {
+		return map[string]any{"item": array[i], "index": i}
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	return &ExecutionResult{
 		Output: map[string]any{
 			"items":    array,
+			"results":  results,
 			"count":    len(array),
 			"original": input,
 		},
@@ -69,30 +117,155 @@ func (n *LoopNode) executeForEach(ctx context.Context, input map[string]any, nod
 	}, nil
 }
 
-func (n *LoopNode) executeWhile(ctx context.Context, input map[string]any, nodeData domain.NodeData, logs []domain.LogEntry) (*ExecutionResult, error) {
+func (n *LoopNode) executeWhile(ctx context.Context, execCtx *ExecutionContext, input map[string]any, nodeData domain.NodeData, logs []domain.LogEntry) (*ExecutionResult, error) {
+	limit := maxIterations(nodeData)
+	scope := expressionScope(execCtx)
+
+	// The body output of one iteration becomes the state for the next one
+	state := input
+	results := []any{}
+	for i := 0; ; i++ {
+		conditionData := make(map[string]any, len(state)+1)
+		for k, v := range state {
+			conditionData[k] = v
+		}
+		conditionData["@index"] = i
+
+		value, err := expression.Eval(nodeData.LoopCondition, scope.WithData(conditionData))
+		if err != nil {
+			return nil, fmt.Errorf("while condition: %w", err)
+		}
+		if !expression.Truthy(value) {
+			break
+		}
+		if i >= limit {
+			return nil, fmt.Errorf("while loop exceeded the limit of %d iterations", limit)
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		output, err := runLoopBody(ctx, execCtx, state)
+		if err != nil {
+			return nil, fmt.Errorf("iteration %d: %w", i, err)
+		}
+		results = append(results, output)
+		if output != nil {
+			state = output
+		}
+	}
+
 	logs = append(logs, domain.LogEntry{
 		Level:     "info",
-		Message:   "While loop execution",
+		Message:   fmt.Sprintf("While loop finished after %d iterations", len(results)),
 		Timestamp: time.Now(),
 	})
-	
+
 	return &ExecutionResult{
-		Output:   input,
+		Output: map[string]any{
+			"results":  results,
+			"count":    len(results),
+			"output":   state,
+			"original": input,
+		},
 		Logs:     logs,
 		NextPort: "done",
 	}, nil
 }
 
-func (n *LoopNode) executeFor(ctx context.Context, input map[string]any, nodeData domain.NodeData, logs []domain.LogEntry) (*ExecutionResult, error) {
+func (n *LoopNode) executeFor(ctx context.Context, execCtx *ExecutionContext, input map[string]any, nodeData domain.NodeData, logs []domain.LogEntry) (*ExecutionResult, error) {
+	if nodeData.LoopCount > maxIterations(nodeData) {
+		return nil, fmt.Errorf("loop count %d exceeds the loop limit of %d", nodeData.LoopCount, maxIterations(nodeData))
+	}
+
 	logs = append(logs, domain.LogEntry{
 		Level:     "info",
-		Message:   "For loop execution",
+		Message:   fmt.Sprintf("Starting for loop with %d iterations", nodeData.LoopCount),
 		Timestamp: time.Now(),
 	})
-	
+
+	results, err := n.runIterations(ctx, execCtx, nodeData.LoopCount, nodeData.LoopConcurrency, func(i int) map[string]any {
+		return map[string]any{"index": i}
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	return &ExecutionResult{
-		Output:   input,
+		Output: map[string]any{
+			"results":  results,
+			"count":    nodeData.LoopCount,
+			"original": input,
+		},
 		Logs:     logs,
 		NextPort: "done",
 	}, nil
 }
+
+// runIterations runs the loop body count times with at most concurrency
+// iterations in flight. Results keep the iteration order; the first failing
+// iteration cancels the remaining ones.
+func (n *LoopNode) runIterations(ctx context.Context, execCtx *ExecutionContext, count int, concurrency int, iterationInput func(i int) map[string]any) ([]any, error) {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]any, count)
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+
+	for i := 0; i < count; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			output, err := runLoopBody(ctx, execCtx, iterationInput(i))
+			if err != nil {
+				once.Do(func() {
+					firstErr = fmt.Errorf("iteration %d: %w", i, err)
+					cancel()
+				})
+				return
+			}
+			results[i] = output
+		}(i)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// runLoopBody runs the subgraph connected to the item port once
+func runLoopBody(ctx context.Context, execCtx *ExecutionContext, input map[string]any) (map[string]any, error) {
+	if execCtx.Branches == nil {
+		return input, nil
+	}
+	return execCtx.Branches.RunBranch(ctx, loopItemPort, input)
+}
+
+func maxIterations(nodeData domain.NodeData) int {
+	if nodeData.LoopMaxIterations > 0 {
+		return nodeData.LoopMaxIterations
+	}
+	return defaultLoopMaxIterations
+}
@@ -5,38 +5,47 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/nodetl/nodetl/internal/domain"
+	"github.com/nodetl/nodetl/internal/expression"
+	"github.com/nodetl/nodetl/internal/fieldpath"
 )
 
 // ResponseNode sends a response back to the caller
 type ResponseNode struct{}
 
 func (n *ResponseNode) GetType() string {
-	return "response"
+	return domain.NodeTypeResponse
 }
 
 func (n *ResponseNode) Validate(nodeData domain.NodeData) error {
 	return nil
 }
 
-// TemplateEngine handles template processing with loops and conditionals
+// TemplateEngine handles template processing with loops and conditionals.
+// Conditions, loop arrays and placeholders are expressions evaluated with
+// the current block's data as bare names.
 type TemplateEngine struct {
-	data map[string]any
+	data  map[string]any
+	scope *expression.Scope
+	err   error // first expression error, reported by Process
 }
 
-// NewTemplateEngine creates a new template engine with data context
-func NewTemplateEngine(data map[string]any) *TemplateEngine {
-	return &TemplateEngine{data: data}
+// NewTemplateEngine creates a new template engine with data context. The
+// scope provides the $ roots and may be nil.
+func NewTemplateEngine(data map[string]any, scope *expression.Scope) *TemplateEngine {
+	return &TemplateEngine{data: data, scope: scope}
 }
 
 // Process processes the template string and returns parsed JSON
 func (te *TemplateEngine) Process(template string) (any, error) {
 	// Process the template
 	processed := te.processString(template, te.data)
+	if te.err != nil {
+		return nil, te.err
+	}
 	
 	// Try to parse as JSON
 	var result any
@@ -65,6 +74,19 @@ func (te *TemplateEngine) processString(template string, contextData map[string]
 	return result
 }
 
+// eval evaluates an expression against the context data, recording the
+// first error
+func (te *TemplateEngine) eval(expr string, contextData map[string]any) any {
+	value, err := expression.Eval(expr, te.scope.WithData(contextData))
+	if err != nil {
+		if te.err == nil {
+			te.err = err
+		}
+		return nil
+	}
+	return value
+}
+
 // processEachBlocks handles {{#each items}}...{{/each}} loops
 func (te *TemplateEngine) processEachBlocks(template string, contextData map[string]any) string {
 	// Regex to match {{#each arrayPath}}content{{/each}}
@@ -80,25 +102,8 @@ func (te *TemplateEngine) processEachBlocks(template string, contextData map[str
 		innerTemplate := submatches[2]
 		
 		// Get the array from data
-		arrayValue := getNestedValue(contextData, arrayPath)
-		if arrayValue == nil {
-			return ""
-		}
-		
-		// Convert to slice
-		var items []any
-		switch v := arrayValue.(type) {
-		case []any:
-			items = v
-		case []map[string]any:
-			for _, item := range v {
-				items = append(items, item)
-			}
-		default:
-			return ""
-		}
-		
-		if len(items) == 0 {
+		items, ok := te.eval(arrayPath, contextData).([]any)
+		if !ok || len(items) == 0 {
 			return ""
 		}
 		
@@ -185,153 +190,24 @@ func (te *TemplateEngine) processUnlessBlocks(template string, contextData map[s
 
 // evaluateCondition evaluates a condition expression
 func (te *TemplateEngine) evaluateCondition(condition string, contextData map[string]any) bool {
-	condition = strings.TrimSpace(condition)
-	
-	// Check for comparison operators
-	operators := []string{"===", "!==", "==", "!=", ">=", "<=", ">", "<"}
-	for _, op := range operators {
-		if strings.Contains(condition, op) {
-			parts := strings.SplitN(condition, op, 2)
-			if len(parts) == 2 {
-				left := te.resolveValue(strings.TrimSpace(parts[0]), contextData)
-				right := te.resolveValue(strings.TrimSpace(parts[1]), contextData)
-				return te.compare(left, right, op)
-			}
-		}
-	}
-	
-	// Simple truthy check
-	value := getNestedValue(contextData, condition)
-	return te.isTruthy(value)
-}
-
-// resolveValue resolves a value from context or returns literal
-func (te *TemplateEngine) resolveValue(expr string, contextData map[string]any) any {
-	expr = strings.TrimSpace(expr)
-	
-	// Check for string literal
-	if (strings.HasPrefix(expr, "\"") && strings.HasSuffix(expr, "\"")) ||
-		(strings.HasPrefix(expr, "'") && strings.HasSuffix(expr, "'")) {
-		return expr[1 : len(expr)-1]
-	}
-	
-	// Check for number
-	if num, err := strconv.ParseFloat(expr, 64); err == nil {
-		return num
-	}
-	
-	// Check for boolean
-	if expr == "true" {
-		return true
-	}
-	if expr == "false" {
-		return false
-	}
-	
-	// Check for null
-	if expr == "null" || expr == "nil" {
-		return nil
-	}
-	
-	// Resolve from context
-	return getNestedValue(contextData, expr)
-}
-
-// compare compares two values with an operator
-func (te *TemplateEngine) compare(left, right any, op string) bool {
-	switch op {
-	case "==", "===":
-		return fmt.Sprintf("%v", left) == fmt.Sprintf("%v", right)
-	case "!=", "!==":
-		return fmt.Sprintf("%v", left) != fmt.Sprintf("%v", right)
-	case ">":
-		return te.toFloat(left) > te.toFloat(right)
-	case ">=":
-		return te.toFloat(left) >= te.toFloat(right)
-	case "<":
-		return te.toFloat(left) < te.toFloat(right)
-	case "<=":
-		return te.toFloat(left) <= te.toFloat(right)
-	}
-	return false
-}
-
-// toFloat converts a value to float64
-func (te *TemplateEngine) toFloat(v any) float64 {
-	switch val := v.(type) {
-	case float64:
-		return val
-	case float32:
-		return float64(val)
-	case int:
-		return float64(val)
-	case int64:
-		return float64(val)
-	case string:
-		if f, err := strconv.ParseFloat(val, 64); err == nil {
-			return f
-		}
-	}
-	return 0
-}
-
-// isTruthy checks if a value is truthy
-func (te *TemplateEngine) isTruthy(v any) bool {
-	if v == nil {
-		return false
-	}
-	switch val := v.(type) {
-	case bool:
-		return val
-	case string:
-		return val != ""
-	case int, int64, float64:
-		return val != 0
-	case []any:
-		return len(val) > 0
-	case map[string]any:
-		return len(val) > 0
-	}
-	return true
+	return expression.Truthy(te.eval(strings.TrimSpace(condition), contextData))
 }
 
 // processPlaceholders handles simple {{field}} placeholders
 func (te *TemplateEngine) processPlaceholders(template string, contextData map[string]any) string {
-	// Regex to match {{field.path}} placeholders (but not block helpers)
-	re := regexp.MustCompile(`"?\{\{([^#/][^}]*)\}\}"?`)
-	
-	return re.ReplaceAllStringFunc(template, func(match string) string {
-		// Extract field path
-		fieldPath := strings.TrimPrefix(match, "\"")
-		fieldPath = strings.TrimSuffix(fieldPath, "\"")
-		fieldPath = strings.TrimPrefix(fieldPath, "{{")
-		fieldPath = strings.TrimSuffix(fieldPath, "}}")
-		fieldPath = strings.TrimSpace(fieldPath)
-		
-		// Skip if it looks like a helper
-		if strings.HasPrefix(fieldPath, "#") || strings.HasPrefix(fieldPath, "/") {
-			return match
-		}
-		
-		// Get value from context
-		value := getNestedValue(contextData, fieldPath)
-		
-		// Convert value to JSON string
-		if value == nil {
-			return "null"
-		}
-		
-		jsonValue, err := json.Marshal(value)
-		if err != nil {
-			return "null"
+	result, err := expression.RenderJSONValues(template, te.scope.WithData(contextData))
+	if err != nil {
+		if te.err == nil {
+			te.err = err
 		}
-		return string(jsonValue)
-	})
+		return template
+	}
+	return result
 }
 
 // processTemplate is the main entry point for template processing
-func processTemplate(template string, data map[string]any) (any, error) {
-	engine := NewTemplateEngine(data)
+func processTemplate(template string, data map[string]any, scope *expression.Scope) (any, error) {
+	engine := NewTemplateEngine(data, scope)
 	return engine.Process(template)
 }
 
@@ -402,7 +278,7 @@ func (n *ResponseNode) buildErrorResponse(execCtx *ExecutionContext, config *dom
 				for k, v := range execCtx.Input {
 					templateData[k] = v
 				}
-				if result, err := processTemplate(specificConfig.Template, templateData); err == nil {
+				if result, err := processTemplate(specificConfig.Template, templateData, expressionScope(execCtx)); err == nil {
 					return statusCode, result
 				}
 			}
@@ -421,12 +297,16 @@ func (n *ResponseNode) buildErrorResponse(execCtx *ExecutionContext, config *dom
 			for k, v := range execCtx.Input {
 				templateData[k] = v
 			}
-			if result, err := processTemplate(config.ErrorConfig.ErrorTemplate, templateData); err == nil {
+			if result, err := processTemplate(config.ErrorConfig.ErrorTemplate, templateData, expressionScope(execCtx)); err == nil {
 				return statusCode, result
 			}
 		}
 	}
 	
+	if statusCode == 0 {
+		statusCode = 500
+	}
+	
 	if includeTraceID && traceID != "" {
 		errorData["traceId"] = traceID
 	}
@@ -472,7 +352,7 @@ func (n *ResponseNode) Execute(ctx context.Context, execCtx *ExecutionContext, n
 			})
 		} else if config.UseTemplate && config.ResponseTemplate != "" {
 			// Use template if enabled
-			templateBody, err := processTemplate(config.ResponseTemplate, execCtx.Input)
+			templateBody, err := processTemplate(config.ResponseTemplate, execCtx.Input, expressionScope(execCtx))
 			if err != nil {
 				logs = append(logs, domain.LogEntry{
 					Level:     "error",
@@ -493,16 +373,44 @@ func (n *ResponseNode) Execute(ctx context.Context, execCtx *ExecutionContext, n
 			if len(config.SelectedFields) > 0 {
 				for _, field := range config.SelectedFields {
 					sourceData := execCtx.Input
-					value := getNestedValue(sourceData, field.FieldPath)
+					// Fields picked from an earlier node read that node's output
+					if field.SourceNodeId != "" {
+						output, ok := execCtx.PreviousData[field.SourceNodeId].(map[string]any)
+						if !ok {
+							logs = append(logs, domain.LogEntry{
+								Level:     "warn",
+								Message:   fmt.Sprintf("Node %s has no output for field %s", field.SourceNodeId, field.FieldPath),
+								Timestamp: time.Now(),
+							})
+						}
+						sourceData = output
+					}
+					value, err := resolveField(expressionScope(execCtx), sourceData, field.FieldPath)
+					if err != nil {
+						logs = append(logs, domain.LogEntry{
+							Level:     "warn",
+							Message:   fmt.Sprintf("Field %s could not be read: %v", field.FieldPath, err),
+							Timestamp: time.Now(),
+						})
+					}
 					targetPath := field.FieldPath
 					if field.Alias != "" {
 						targetPath = field.Alias
 					}
-					setNestedValue(body, targetPath, value)
+					if err := fieldpath.Set(body, targetPath, value); err != nil {
+						logs = append(logs, domain.LogEntry{
+							Level:     "warn",
+							Message:   fmt.Sprintf("Field %s could not be set: %v", targetPath, err),
+							Timestamp: time.Now(),
+						})
+					}
 				}
 				finalBody = body
 			}
 		}
+	} else if execCtx.Error != nil {
+		// Without a response config, errors still get the default error body
+		statusCode, finalBody = n.buildErrorResponse(execCtx, &domain.ResponseConfig{}, execCtx.Error.Type, execCtx.Error.Message, execCtx.Error.StatusCode)
 	}
 	
 	response["statusCode"] = statusCode
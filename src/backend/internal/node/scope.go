@@ -0,0 +1,82 @@
+package node
+
+import (
+	"strings"
+
+	"github.com/nodetl/nodetl/internal/expression"
+	"github.com/nodetl/nodetl/internal/fieldpath"
+)
+
+// expressionScope returns the values expressions can refer to while a node
+// runs. Bare names resolve against the node's input.
+func expressionScope(execCtx *ExecutionContext) *expression.Scope {
+	vars := execCtx.Variables
+	if vars == nil {
+		vars = map[string]any{}
+	}
+
+	nodes := make(map[string]any, len(execCtx.PreviousData))
+	for id, output := range execCtx.PreviousData {
+		nodes[id] = map[string]any{"output": output}
+	}
+
+	var execErr any
+	if execCtx.Error != nil {
+		execErr = execCtx.Error.ToMap()
+	}
+
+	return &expression.Scope{
+		Roots: map[string]any{
+			"$input":   execCtx.Input,
+			"$trigger": execCtx.TriggerInput,
+			"$vars":    vars,
+			"$node":    nodes,
+			"$execution": map[string]any{
+				"id":          execCtx.ExecutionID,
+				"workflowId":  execCtx.WorkflowID,
+				"nodeId":      execCtx.NodeID,
+				"traceId":     execCtx.TraceID,
+				"environment": execCtx.Environment,
+			},
+			"$error": execErr,
+		},
+		Data: execCtx.Input,
+	}
+}
+
+// isExpression reports whether a field reference is an expression rather
+// than a field path. $input.total and $node["id"] are expressions, while $
+// and $.items[0] are JSONPath style field paths.
+func isExpression(path string) bool {
+	if expression.IsTemplate(path) {
+		return true
+	}
+	return strings.HasPrefix(path, "$") && path != "$" &&
+		!strings.HasPrefix(path, "$.") && !strings.HasPrefix(path, "$[")
+}
+
+// resolveField reads a value referenced by a node setting. Field paths such
+// as items[0].sku are read from data; $-rooted expressions and {{ }}
+// templates are evaluated in the scope.
+func resolveField(scope *expression.Scope, data map[string]any, path string) (any, error) {
+	if expression.IsTemplate(path) {
+		return expression.Resolve(path, scope.WithData(data))
+	}
+	if isExpression(path) {
+		return expression.Eval(path, scope.WithData(data))
+	}
+	return fieldpath.Get(data, path)
+}
+
+// validateField checks the syntax of a field reference
+func validateField(path string) error {
+	if isExpression(path) {
+		if expression.IsTemplate(path) {
+			return nil
+		}
+		_, err := expression.Compile(path)
+		return err
+	}
+	_, err := fieldpath.Parse(path)
+	return err
+}
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/nodetl/nodetl/internal/domain"
+	"github.com/nodetl/nodetl/internal/expression"
+	"github.com/nodetl/nodetl/internal/fieldpath"
 )
 
 // TransformNode transforms data according to mapping rules
@@ -20,23 +22,54 @@ func (n *TransformNode) Validate(nodeData domain.NodeData) error {
 	if len(nodeData.MappingRules) == 0 {
 		return fmt.Errorf("transform node requires at least one mapping rule")
 	}
-	return nil
+	return validateMappingRules(nodeData.MappingRules)
 }
 
 func (n *TransformNode) Execute(ctx context.Context, execCtx *ExecutionContext, nodeData domain.NodeData) (*ExecutionResult, error) {
-	input := execCtx.Input
+	output, logs := applyMappingRules(execCtx.Input, nodeData.MappingRules, expressionScope(execCtx))
+
+	return &ExecutionResult{
+		Output:   output,
+		Logs:     logs,
+		NextPort: "output",
+	}, nil
+}
+
+// validateMappingRules checks the source and target fields of mapping rules
+func validateMappingRules(rules []domain.MappingRule) error {
+	for _, rule := range rules {
+		if err := validateField(rule.SourceField); err != nil {
+			return fmt.Errorf("mapping rule source: %w", err)
+		}
+		if _, err := fieldpath.Parse(rule.TargetField); err != nil {
+			return fmt.Errorf("mapping rule target: %w", err)
+		}
+	}
+	return nil
+}
+
+// applyMappingRules builds a new object from the input according to the rules.
+// Source fields may be plain paths, $-rooted expressions or templates.
+func applyMappingRules(input map[string]any, rules []domain.MappingRule, scope *expression.Scope) (map[string]any, []domain.LogEntry) {
 	output := make(map[string]any)
 	logs := []domain.LogEntry{}
 
-	for _, rule := range nodeData.MappingRules {
+	for _, rule := range rules {
 		// Get source value
-		sourceValue := getNestedValue(input, rule.SourceField)
+		sourceValue, err := resolveField(scope, input, rule.SourceField)
+		if err != nil {
+			logs = append(logs, domain.LogEntry{
+				Level:     "warn",
+				Message:   fmt.Sprintf("Source field %s could not be resolved: %v", rule.SourceField, err),
+				Timestamp: time.Now(),
+			})
+		}
 		
 		// Apply transformation if specified
 		transformedValue := sourceValue
 		if rule.Transform != "" {
 			var err error
-			transformedValue, err = applyTransform(sourceValue, rule.Transform)
+			transformedValue, err = applyTransform(sourceValue, rule.Transform, scope.WithData(input))
 			if err != nil {
 				logs = append(logs, domain.LogEntry{
 					Level:     "warn",
@@ -56,7 +89,14 @@ func (n *TransformNode) Execute(ctx context.Context, execCtx *ExecutionContext,
 		}
 		
 		// Set target value
-		setNestedValue(output, rule.TargetField, transformedValue)
+		if err := fieldpath.Set(output, rule.TargetField, transformedValue); err != nil {
+			logs = append(logs, domain.LogEntry{
+				Level:     "warn",
+				Message:   fmt.Sprintf("Target field %s could not be set: %v", rule.TargetField, err),
+				Timestamp: time.Now(),
+			})
+			continue
+		}
 		
 		logs = append(logs, domain.LogEntry{
 			Level:     "debug",
@@ -66,60 +106,18 @@ func (n *TransformNode) Execute(ctx context.Context, execCtx *ExecutionContext,
 		})
 	}
 
-	return &ExecutionResult{
-		Output:   output,
-		Logs:     logs,
-		NextPort: "output",
-	}, nil
-}
-
-// getNestedValue gets a value from a nested map using dot notation
-func getNestedValue(data map[string]any, path string) any {
-	parts := strings.Split(path, ".")
-	current := any(data)
-	
-	for _, part := range parts {
-		if current == nil {
-			return nil
-		}
-		
-		switch v := current.(type) {
-		case map[string]any:
-			current = v[part]
-		default:
-			return nil
-		}
-	}
-	
-	return current
+	return output, logs
 }
 
-// setNestedValue sets a value in a nested map using dot notation
-func setNestedValue(data map[string]any, path string, value any) {
-	parts := strings.Split(path, ".")
-	current := data
-	
-	for i, part := range parts {
-		if i == len(parts)-1 {
-			current[part] = value
-		} else {
-			if _, ok := current[part]; !ok {
-				current[part] = make(map[string]any)
-			}
-			if next, ok := current[part].(map[string]any); ok {
-				current = next
-			} else {
-				// Can't traverse further, create new map
-				newMap := make(map[string]any)
-				current[part] = newMap
-				current = newMap
-			}
-		}
+// applyTransform applies a named transformation to a value. Transforms
+// starting with = or a $ root are expressions in which $value is the source
+// value, e.g. =formatDate($value, "YYYY-MM-DD") or $value * 100. Other names,
+// such as the direct and formula connection types saved by the UI, leave the
+// value unchanged.
+func applyTransform(value any, transform string, scope *expression.Scope) (any, error) {
+	if expr, ok := transformExpression(transform); ok {
+		return expression.Eval(expr, scope.WithRoot("$value", value))
 	}
-}
-
-// applyTransform applies a transformation to a value
-func applyTransform(value any, transform string) (any, error) {
 	if value == nil {
 		return nil, nil
 	}
@@ -159,8 +157,20 @@ func applyTransform(value any, transform string) (any, error) {
 			return strings.TrimSpace(s), nil
 		}
 		return value, nil
-	default:
-		// Direct copy if no transform specified
-		return value, nil
+	case "parseDate":
+		return expression.Eval("toDate($value)", scope.WithRoot("$value", value))
+	}
+	return value, nil
+}
+
+// transformExpression returns the expression of a transform marked as one
+func transformExpression(transform string) (string, bool) {
+	trimmed := strings.TrimSpace(transform)
+	if expr, ok := strings.CutPrefix(trimmed, "="); ok {
+		return strings.TrimSpace(expr), true
+	}
+	if strings.HasPrefix(trimmed, "$") {
+		return trimmed, true
 	}
+	return "", false
 }
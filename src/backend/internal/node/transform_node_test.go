@@ -0,0 +1,37 @@
+package node
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nodetl/nodetl/internal/domain"
+)
+
+func TestApplyMappingRulesPassesThroughNamedTransforms(t *testing.T) {
+	input := map[string]any{
+		"id":    "c-42",
+		"name":  "Ada",
+		"total": 12.5,
+	}
+	execCtx := &ExecutionContext{Input: input}
+
+	rules := []domain.MappingRule{
+		{SourceField: "id", TargetField: "customer.id", Transform: "direct"},
+		{SourceField: "name", TargetField: "customer.name", Transform: "formula"},
+		{SourceField: "total", TargetField: "amount", Transform: "transform"},
+		{SourceField: "total", TargetField: "cents", Transform: "=$value * 100"},
+		{SourceField: "name", TargetField: "upper", Transform: "$value | upper"},
+	}
+
+	output, _ := applyMappingRules(input, rules, expressionScope(execCtx))
+
+	want := map[string]any{
+		"customer": map[string]any{"id": "c-42", "name": "Ada"},
+		"amount":   12.5,
+		"cents":    1250.0,
+		"upper":    "ADA",
+	}
+	if !reflect.DeepEqual(output, want) {
+		t.Fatalf("applyMappingRules() = %v, want %v", output, want)
+	}
+}
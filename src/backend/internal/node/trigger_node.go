@@ -2,9 +2,12 @@ package node
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/nodetl/nodetl/internal/domain"
+	"github.com/robfig/cron/v3"
 )
 
 // TriggerNode is the entry point of a workflow
@@ -15,9 +18,42 @@ func (n *TriggerNode) GetType() string {
 }
 
 func (n *TriggerNode) Validate(nodeData domain.NodeData) error {
+	if nodeData.TriggerType == "schedule" {
+		if nodeData.Schedule == "" {
+			return fmt.Errorf("schedule trigger requires a cron expression")
+		}
+		if _, err := ParseSchedule(nodeData.Schedule, nodeData.Timezone); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// scheduleParser accepts standard five-field cron expressions, an optional
+// leading seconds field and descriptors such as @daily or @every 1h
+var scheduleParser = cron.NewParser(
+	cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
+)
+
+// ParseSchedule parses the cron expression of a schedule trigger. The time
+// zone can be given separately or inline with a CRON_TZ= prefix; schedules
+// without one run in UTC.
+func ParseSchedule(expr string, timezone string) (cron.Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if !strings.HasPrefix(expr, "CRON_TZ=") && !strings.HasPrefix(expr, "TZ=") {
+		if timezone == "" {
+			timezone = "UTC"
+		}
+		expr = "CRON_TZ=" + timezone + " " + expr
+	}
+
+	schedule, err := scheduleParser.Parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: %w", expr, err)
+	}
+	return schedule, nil
+}
+
 func (n *TriggerNode) Execute(ctx context.Context, execCtx *ExecutionContext, nodeData domain.NodeData) (*ExecutionResult, error) {
 	// Trigger node simply passes through the input data
 	return &ExecutionResult{
@@ -0,0 +1,253 @@
+// Package redact hides sensitive values in execution data before it is
+// stored or returned by the API. Values are redacted by header name inside
+// any "headers" object, such as the request headers webhooks pass to
+// workflows, and by field path patterns such as customer.ssn or ..password.
+package redact
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nodetl/nodetl/internal/domain"
+	"github.com/nodetl/nodetl/internal/fieldpath"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Placeholder replaces redacted values
+const Placeholder = "[REDACTED]"
+
+// DefaultHeaders are the header names redacted by default
+var DefaultHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+	"X-Auth-Token",
+	"X-Csrf-Token",
+}
+
+// Rules decide which values of execution data are redacted. A nil *Rules
+// redacts nothing.
+type Rules struct {
+	headers map[string]bool // lowercase header names
+	paths   []*fieldpath.Path
+}
+
+// New creates rules that redact the given header names and field paths
+func New(headers []string, paths []string) (*Rules, error) {
+	r := &Rules{headers: make(map[string]bool)}
+	for _, name := range headers {
+		if name = strings.TrimSpace(name); name != "" {
+			r.headers[strings.ToLower(name)] = true
+		}
+	}
+	return r.WithPaths(paths...)
+}
+
+// WithPaths returns a copy of the rules that also redacts the given field
+// paths. Paths that cannot be parsed are skipped and reported in the error,
+// so the valid ones still apply.
+func (r *Rules) WithPaths(paths ...string) (*Rules, error) {
+	out := &Rules{}
+	if r != nil {
+		out.headers = r.headers
+		out.paths = append(out.paths, r.paths...)
+	}
+
+	var errs []error
+	for _, src := range paths {
+		if strings.TrimSpace(src) == "" {
+			continue
+		}
+		p, err := fieldpath.Parse(src)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if strings.TrimSpace(src) == "$" {
+			errs = append(errs, fmt.Errorf("invalid path %q: cannot redact the root", src))
+			continue
+		}
+		out.paths = append(out.paths, p)
+	}
+	return out, errors.Join(errs...)
+}
+
+// ValidatePaths checks that field paths can be used for redaction
+func ValidatePaths(paths []string) error {
+	_, err := (&Rules{}).WithPaths(paths...)
+	return err
+}
+
+// Value returns data with the sensitive values replaced by the placeholder.
+// data itself is not changed; the objects and arrays leading to redacted
+// values are copied.
+func (r *Rules) Value(data any) any {
+	if r == nil || data == nil {
+		return data
+	}
+	if len(r.headers) > 0 {
+		data, _ = r.redactHeaders(data)
+	}
+	for _, p := range r.paths {
+		data = p.Replace(data, placeholder)
+	}
+	return data
+}
+
+// Map redacts an object such as the input or output of a node
+func (r *Rules) Map(data map[string]any) map[string]any {
+	if r == nil || data == nil {
+		return data
+	}
+	if redacted, ok := r.Value(data).(map[string]any); ok {
+		return redacted
+	}
+	return data
+}
+
+// Execution returns a copy of an execution with its input, output and node
+// logs redacted
+func (r *Rules) Execution(execution *domain.Execution) *domain.Execution {
+	if r == nil {
+		return execution
+	}
+	redacted := *execution
+	redacted.Input = r.Map(execution.Input)
+	redacted.Output = r.Map(execution.Output)
+	redacted.NodeLogs = make([]domain.NodeExecutionLog, len(execution.NodeLogs))
+	for i, nodeLog := range execution.NodeLogs {
+		redacted.NodeLogs[i] = r.NodeLog(nodeLog)
+	}
+	return &redacted
+}
+
+// NodeLog returns a node log with its input and output redacted
+func (r *Rules) NodeLog(nodeLog domain.NodeExecutionLog) domain.NodeExecutionLog {
+	nodeLog.Input = r.Map(nodeLog.Input)
+	nodeLog.Output = r.Map(nodeLog.Output)
+	return nodeLog
+}
+
+func placeholder(any) any {
+	return Placeholder
+}
+
+// redactHeaders replaces the sensitive entries of every "headers" object
+// found in data
+func (r *Rules) redactHeaders(data any) (any, bool) {
+	return mapFields(data, func(key string, value any) (any, bool) {
+		if strings.EqualFold(key, "headers") {
+			if redacted, ok := r.redactHeaderValues(value); ok {
+				return redacted, true
+			}
+		}
+		return r.redactHeaders(value)
+	})
+}
+
+// redactHeaderValues replaces the values of sensitive header names
+func (r *Rules) redactHeaderValues(headers any) (any, bool) {
+	if h, ok := headers.(map[string]string); ok {
+		var out map[string]string
+		for name := range h {
+			if r.headers[strings.ToLower(name)] {
+				if out == nil {
+					out = make(map[string]string, len(h))
+					for k, v := range h {
+						out[k] = v
+					}
+				}
+				out[name] = Placeholder
+			}
+		}
+		if out == nil {
+			return headers, false
+		}
+		return out, true
+	}
+
+	return mapFields(headers, func(name string, value any) (any, bool) {
+		if r.headers[strings.ToLower(name)] {
+			return Placeholder, true
+		}
+		return value, false
+	})
+}
+
+// mapFields applies fn to the fields of every object in data, descending
+// through arrays, and returns a copy if any field changed. fn receives each
+// field of an object and decides itself whether to descend into it.
+func mapFields(data any, fn func(key string, value any) (any, bool)) (any, bool) {
+	switch v := data.(type) {
+	case map[string]any:
+		var out map[string]any
+		for key, value := range v {
+			if replaced, changed := fn(key, value); changed {
+				if out == nil {
+					out = make(map[string]any, len(v))
+					for k, val := range v {
+						out[k] = val
+					}
+				}
+				out[key] = replaced
+			}
+		}
+		if out == nil {
+			return data, false
+		}
+		return out, true
+
+	case primitive.M:
+		replaced, changed := mapFields(map[string]any(v), fn)
+		if !changed {
+			return data, false
+		}
+		return primitive.M(replaced.(map[string]any)), true
+
+	case primitive.D:
+		var out primitive.D
+		for i, e := range v {
+			if replaced, changed := fn(e.Key, e.Value); changed {
+				if out == nil {
+					out = make(primitive.D, len(v))
+					copy(out, v)
+				}
+				out[i].Value = replaced
+			}
+		}
+		if out == nil {
+			return data, false
+		}
+		return out, true
+
+	case []any:
+		return mapItems(v, fn)
+
+	case primitive.A:
+		replaced, changed := mapItems(v, fn)
+		if !changed {
+			return data, false
+		}
+		return primitive.A(replaced.([]any)), true
+	}
+	return data, false
+}
+
+func mapItems(items []any, fn func(key string, value any) (any, bool)) (any, bool) {
+	var out []any
+	for i, item := range items {
+		if replaced, changed := mapFields(item, fn); changed {
+			if out == nil {
+				out = append([]any(nil), items...)
+			}
+			out[i] = replaced
+		}
+	}
+	if out == nil {
+		return items, false
+	}
+	return out, true
+}
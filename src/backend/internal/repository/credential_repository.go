@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/nodetl/nodetl/internal/domain"
+	"github.com/nodetl/nodetl/pkg/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrCredentialNotFound      = errors.New("credential not found")
+	ErrCredentialAlreadyExists = errors.New("credential with this name already exists")
+)
+
+type credentialRepository struct {
+	collection *mongo.Collection
+}
+
+// NewCredentialRepository creates a new credential repository
+func NewCredentialRepository(client *mongodb.Client) CredentialRepository {
+	collection := client.Collection(mongodb.CollectionCredentials)
+
+	// Create indexes
+	ctx := context.Background()
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "name", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+	_, _ = collection.Indexes().CreateMany(ctx, indexes)
+
+	return &credentialRepository{collection: collection}
+}
+
+func (r *credentialRepository) Create(ctx context.Context, credential *domain.Credential) error {
+	now := time.Now()
+	credential.CreatedAt = now
+	credential.UpdatedAt = now
+
+	result, err := r.collection.InsertOne(ctx, credential)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrCredentialAlreadyExists
+		}
+		return err
+	}
+
+	credential.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *credentialRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Credential, error) {
+	var credential domain.Credential
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&credential)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrCredentialNotFound
+		}
+		return nil, err
+	}
+	return &credential, nil
+}
+
+func (r *credentialRepository) GetAll(ctx context.Context) ([]domain.Credential, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var credentials []domain.Credential
+	if err := cursor.All(ctx, &credentials); err != nil {
+		return nil, err
+	}
+
+	return credentials, nil
+}
+
+func (r *credentialRepository) Update(ctx context.Context, credential *domain.Credential) error {
+	credential.UpdatedAt = time.Now()
+
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": credential.ID}, credential)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrCredentialAlreadyExists
+		}
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrCredentialNotFound
+	}
+
+	return nil
+}
+
+func (r *credentialRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return ErrCredentialNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/nodetl/nodetl/internal/domain"
+	"github.com/nodetl/nodetl/pkg/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type executionJobRepository struct {
+	collection *mongo.Collection
+}
+
+// NewExecutionJobRepository creates a new execution job repository
+func NewExecutionJobRepository(client *mongodb.Client) ExecutionJobRepository {
+	collection := client.Collection(mongodb.CollectionExecutionJobs)
+
+	// Create indexes
+	ctx := context.Background()
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: 1}},
+		},
+		{
+			Keys:    bson.D{{Key: "execution_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "locked_at", Value: 1}},
+		},
+	}
+	_, _ = collection.Indexes().CreateMany(ctx, indexes)
+
+	return &executionJobRepository{collection: collection}
+}
+
+func (r *executionJobRepository) Enqueue(ctx context.Context, job *domain.ExecutionJob) error {
+	job.Status = domain.ExecutionJobStatusQueued
+	job.CreatedAt = time.Now()
+
+	result, err := r.collection.InsertOne(ctx, job)
+	if err != nil {
+		return err
+	}
+
+	job.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// ClaimNext atomically takes the oldest queued job for the given worker.
+// It returns nil when the queue is empty.
+func (r *executionJobRepository) ClaimNext(ctx context.Context, workerID string) (*domain.ExecutionJob, error) {
+	now := time.Now()
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "created_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var job domain.ExecutionJob
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{"status": domain.ExecutionJobStatusQueued},
+		bson.M{
+			"$set": bson.M{
+				"status":    domain.ExecutionJobStatusRunning,
+				"worker_id": workerID,
+				"locked_at": now,
+			},
+			"$inc": bson.M{"attempts": 1},
+		},
+		opts,
+	).Decode(&job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *executionJobRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+// Requeue queues a job for an execution again, replacing any job left behind
+// by a worker that died
+func (r *executionJobRepository) Requeue(ctx context.Context, job *domain.ExecutionJob) error {
+	job.Status = domain.ExecutionJobStatusQueued
+	job.CreatedAt = time.Now()
+	job.WorkerID = ""
+	job.LockedAt = nil
+
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"execution_id": job.ExecutionID},
+		bson.M{
+			"$set": bson.M{
+				"workflow_id":  job.WorkflowID,
+				"trigger_path": job.TriggerPath,
+				"status":       job.Status,
+				"created_at":   job.CreatedAt,
+			},
+			"$unset": bson.M{"worker_id": "", "locked_at": ""},
+		},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// FindStaleClaims returns jobs a worker claimed before the given time and
+// has not deleted yet
+func (r *executionJobRepository) FindStaleClaims(ctx context.Context, lockedBefore time.Time, limit int) ([]domain.ExecutionJob, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "locked_at", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, bson.M{
+		"status":    domain.ExecutionJobStatusRunning,
+		"locked_at": bson.M{"$lt": lockedBefore},
+	}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var jobs []domain.ExecutionJob
+	if err := cursor.All(ctx, &jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// Release queues a claimed job again, unless it was claimed anew since it was
+// read. It reports whether the job was released.
+func (r *executionJobRepository) Release(ctx context.Context, job *domain.ExecutionJob) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{
+			"_id":       job.ID,
+			"status":    domain.ExecutionJobStatusRunning,
+			"locked_at": job.LockedAt,
+		},
+		bson.M{
+			"$set":   bson.M{"status": domain.ExecutionJobStatusQueued},
+			"$unset": bson.M{"worker_id": "", "locked_at": ""},
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+func (r *executionJobRepository) DeleteByExecutionID(ctx context.Context, executionID primitive.ObjectID) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"execution_id": executionID})
+	return err
+}
@@ -2,6 +2,11 @@ package repository
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
 	"time"
 
 	"github.com/nodetl/nodetl/internal/domain"
@@ -17,9 +22,43 @@ type executionRepository struct {
 }
 
 func NewExecutionRepository(client *mongodb.Client) ExecutionRepository {
-	return &executionRepository{
-		collection: client.Collection(mongodb.CollectionExecutions),
+	collection := client.Collection(mongodb.CollectionExecutions)
+
+	// Create indexes
+	ctx := context.Background()
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "heartbeat_at", Value: 1}},
+		},
+		// Execution search filters by workflow and sorts by start time or
+		// duration; _id breaks ties between pages
+		{
+			Keys: bson.D{{Key: "workflow_id", Value: 1}, {Key: "started_at", Value: -1}, {Key: "_id", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "workflow_id", Value: 1}, {Key: "duration", Value: -1}, {Key: "_id", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "workflow_id", Value: 1}, {Key: "status", Value: 1}, {Key: "started_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "workflow_id", Value: 1}, {Key: "trigger_type", Value: 1}, {Key: "started_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "workflow_id", Value: 1}, {Key: "node_logs.node_id", Value: 1}, {Key: "node_logs.status", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "metadata.traceId", Value: 1}},
+		},
+		// Each execution stores when its retention policy lets it expire
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
 	}
+	_, _ = collection.Indexes().CreateMany(ctx, indexes)
+
+	return &executionRepository{collection: collection}
 }
 
 func (r *executionRepository) Create(ctx context.Context, execution *domain.Execution) error {
@@ -46,68 +85,179 @@ func (r *executionRepository) GetByID(ctx context.Context, id primitive.ObjectID
 	return &execution, nil
 }
 
-func (r *executionRepository) GetByWorkflowID(ctx context.Context, workflowID primitive.ObjectID, page, pageSize int) ([]domain.Execution, int64, error) {
-	query := bson.M{"workflow_id": workflowID}
+// Execution sort fields
+const (
+	ExecutionSortStartedAt = "startedAt"
+	ExecutionSortDuration  = "duration"
+)
 
-	total, err := r.collection.CountDocuments(ctx, query)
+// ErrInvalidCursor is returned for a cursor that was not returned by a
+// search with the same sort
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// executionSortFields maps the sort fields to their stored names
+var executionSortFields = map[string]string{
+	ExecutionSortStartedAt: "started_at",
+	ExecutionSortDuration:  "duration",
+}
+
+// executionCursor is the position after the last execution of a page
+type executionCursor struct {
+	SortBy    string             `json:"s"`
+	StartedAt time.Time          `json:"t,omitempty"`
+	Duration  int64              `json:"d,omitempty"`
+	ID        primitive.ObjectID `json:"id"`
+}
+
+func encodeExecutionCursor(sortBy string, execution *domain.Execution) string {
+	cursor := executionCursor{SortBy: sortBy, ID: execution.ID}
+	if sortBy == ExecutionSortDuration {
+		cursor.Duration = execution.Duration
+	} else {
+		cursor.StartedAt = execution.StartedAt
+	}
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeExecutionCursor(value, sortBy string) (*executionCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
 	if err != nil {
-		return nil, 0, err
+		return nil, ErrInvalidCursor
+	}
+	var cursor executionCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.SortBy != sortBy {
+		return nil, fmt.Errorf("%w: it was returned for a search sorted by %s", ErrInvalidCursor, cursor.SortBy)
 	}
+	return &cursor, nil
+}
 
-	opts := options.Find().
-		SetSort(bson.D{{Key: "started_at", Value: -1}})
+// executionQuery builds the query matching a filter, without its cursor
+func executionQuery(filter ExecutionFilter) bson.M {
+	query := bson.M{}
+
+	if len(filter.WorkflowIDs) == 1 {
+		query["workflow_id"] = filter.WorkflowIDs[0]
+	} else {
+		query["workflow_id"] = bson.M{"$in": filter.WorkflowIDs}
+	}
+	if len(filter.Statuses) > 0 {
+		query["status"] = bson.M{"$in": filter.Statuses}
+	}
+	if len(filter.TriggerTypes) > 0 {
+		query["trigger_type"] = bson.M{"$in": filter.TriggerTypes}
+	}
 
-	if pageSize > 0 {
-		opts.SetLimit(int64(pageSize))
-		opts.SetSkip(int64((page - 1) * pageSize))
+	startedAt := bson.M{}
+	if filter.StartedFrom != nil {
+		startedAt["$gte"] = *filter.StartedFrom
+	}
+	if filter.StartedTo != nil {
+		startedAt["$lt"] = *filter.StartedTo
+	}
+	if len(startedAt) > 0 {
+		query["started_at"] = startedAt
 	}
 
-	cursor, err := r.collection.Find(ctx, query, opts)
-	if err != nil {
-		return nil, 0, err
+	duration := bson.M{}
+	if filter.MinDuration != nil {
+		duration["$gte"] = *filter.MinDuration
+	}
+	if filter.MaxDuration != nil {
+		duration["$lte"] = *filter.MaxDuration
+	}
+	if len(duration) > 0 {
+		query["duration"] = duration
 	}
-	defer cursor.Close(ctx)
 
-	var executions []domain.Execution
-	if err := cursor.All(ctx, &executions); err != nil {
-		return nil, 0, err
+	if filter.TraceID != "" {
+		query["metadata.traceId"] = filter.TraceID
+	}
+	// Nodes that failed without failing the execution, such as those set to
+	// continue on error, are only recorded in the node logs
+	if filter.FailedNode != "" {
+		query["node_logs"] = bson.M{"$elemMatch": bson.M{
+			"node_id": filter.FailedNode,
+			"status":  domain.ExecutionStatusFailed,
+		}}
+	}
+	if filter.ErrorText != "" {
+		query["error.message"] = bson.M{"$regex": regexp.QuoteMeta(filter.ErrorText), "$options": "i"}
 	}
 
-	return executions, total, nil
+	return query
 }
 
-func (r *executionRepository) GetByWorkflowIDs(ctx context.Context, workflowIDs []primitive.ObjectID, page, pageSize int) ([]domain.Execution, int64, error) {
-	if len(workflowIDs) == 0 {
-		return []domain.Execution{}, 0, nil
+// Search returns a page of the executions matching a filter. Pages follow
+// each other through cursors, which stay stable while new executions are
+// stored; without a cursor the page number is used.
+func (r *executionRepository) Search(ctx context.Context, filter ExecutionFilter) (*ExecutionPage, error) {
+	if len(filter.WorkflowIDs) == 0 {
+		return &ExecutionPage{Executions: []domain.Execution{}}, nil
+	}
+	if filter.SortBy == "" {
+		filter.SortBy = ExecutionSortStartedAt
+	}
+	sortField, ok := executionSortFields[filter.SortBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown sort field %q", filter.SortBy)
 	}
 
-	query := bson.M{"workflow_id": bson.M{"$in": workflowIDs}}
+	query := executionQuery(filter)
 
 	total, err := r.collection.CountDocuments(ctx, query)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 
+	direction, after := -1, "$lt"
+	if filter.Ascending {
+		direction, after = 1, "$gt"
+	}
 	opts := options.Find().
-		SetSort(bson.D{{Key: "started_at", Value: -1}})
+		SetSort(bson.D{{Key: sortField, Value: direction}, {Key: "_id", Value: direction}})
 
-	if pageSize > 0 {
-		opts.SetLimit(int64(pageSize))
-		opts.SetSkip(int64((page - 1) * pageSize))
+	if filter.Cursor != "" {
+		cursor, err := decodeExecutionCursor(filter.Cursor, filter.SortBy)
+		if err != nil {
+			return nil, err
+		}
+		var value any = cursor.StartedAt
+		if filter.SortBy == ExecutionSortDuration {
+			value = cursor.Duration
+		}
+		query = bson.M{"$and": bson.A{query, bson.M{"$or": bson.A{
+			bson.M{sortField: bson.M{after: value}},
+			bson.M{sortField: value, "_id": bson.M{after: cursor.ID}},
+		}}}}
+	} else if filter.Page > 1 && filter.PageSize > 0 {
+		opts.SetSkip(int64((filter.Page - 1) * filter.PageSize))
+	}
+	if filter.PageSize > 0 {
+		// One more to know whether there is a next page
+		opts.SetLimit(int64(filter.PageSize) + 1)
 	}
 
 	cursor, err := r.collection.Find(ctx, query, opts)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 	defer cursor.Close(ctx)
 
-	var executions []domain.Execution
+	executions := []domain.Execution{}
 	if err := cursor.All(ctx, &executions); err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 
-	return executions, total, nil
+	page := &ExecutionPage{Executions: executions, Total: total}
+	if filter.PageSize > 0 && len(executions) > filter.PageSize {
+		page.Executions = executions[:filter.PageSize]
+		page.NextCursor = encodeExecutionCursor(filter.SortBy, &page.Executions[filter.PageSize-1])
+	}
+	return page, nil
 }
 
 func (r *executionRepository) Update(ctx context.Context, execution *domain.Execution) error {
@@ -115,6 +265,106 @@ func (r *executionRepository) Update(ctx context.Context, execution *domain.Exec
 	return err
 }
 
+// ReplaceIfStatus stores the execution only if the stored one still has the
+// given status. It reports whether it was stored, so that of two instances
+// changing the status only one wins.
+func (r *executionRepository) ReplaceIfStatus(ctx context.Context, execution *domain.Execution, status domain.ExecutionStatus) (bool, error) {
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": execution.ID, "status": status}, execution)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// AppendNodeLog adds the log of a finished node to a stored execution while
+// it is still running
+func (r *executionRepository) AppendNodeLog(ctx context.Context, id primitive.ObjectID, nodeLog domain.NodeExecutionLog) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id},
+		bson.M{"$push": bson.M{"node_logs": nodeLog}},
+	)
+	return err
+}
+
+// Heartbeat records that a running execution is still being worked on. It
+// reports whether cancelling the execution was requested.
+func (r *executionRepository) Heartbeat(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	opts := options.FindOneAndUpdate().SetProjection(bson.M{"cancel_requested": 1})
+
+	var execution domain.Execution
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": id, "status": domain.ExecutionStatusRunning},
+		bson.M{"$set": bson.M{"heartbeat_at": time.Now()}},
+		opts,
+	).Decode(&execution)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return execution.CancelRequested, nil
+}
+
+// RequestCancel flags a running execution so that the instance running it
+// cancels it. It reports whether the execution was still running.
+func (r *executionRepository) RequestCancel(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "status": domain.ExecutionStatusRunning},
+		bson.M{"$set": bson.M{"cancel_requested": true}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// staleQuery matches running executions without a heartbeat since the given
+// time. Executions started before heartbeats were recorded fall back to
+// their start time.
+func staleQuery(staleBefore time.Time) bson.M {
+	return bson.M{
+		"status": domain.ExecutionStatusRunning,
+		"$or": bson.A{
+			bson.M{"heartbeat_at": bson.M{"$lt": staleBefore}},
+			bson.M{"heartbeat_at": bson.M{"$exists": false}, "started_at": bson.M{"$lt": staleBefore}},
+		},
+	}
+}
+
+// FindStale returns running executions without a heartbeat since the given time
+func (r *executionRepository) FindStale(ctx context.Context, staleBefore time.Time, limit int) ([]domain.Execution, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "started_at", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, staleQuery(staleBefore), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var executions []domain.Execution
+	if err := cursor.All(ctx, &executions); err != nil {
+		return nil, err
+	}
+	return executions, nil
+}
+
+// ReplaceIfStale stores the execution only if the stored one is still running
+// without a heartbeat since the given time. It reports whether it was stored,
+// so that only one instance recovers each execution.
+func (r *executionRepository) ReplaceIfStale(ctx context.Context, execution *domain.Execution, staleBefore time.Time) (bool, error) {
+	query := staleQuery(staleBefore)
+	query["_id"] = execution.ID
+
+	result, err := r.collection.ReplaceOne(ctx, query, execution)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
 func (r *executionRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
 	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
 	return err
@@ -138,3 +388,259 @@ func (r *executionRepository) GetLatest(ctx context.Context, workflowID primitiv
 
 	return executions, nil
 }
+
+// finishedStatuses are the statuses of executions retention may delete
+var finishedStatuses = bson.A{
+	domain.ExecutionStatusCompleted,
+	domain.ExecutionStatusFailed,
+	domain.ExecutionStatusCancelled,
+}
+
+// DeleteFinishedBefore deletes the finished executions of a workflow started
+// before the given time. Pending and running executions are kept.
+func (r *executionRepository) DeleteFinishedBefore(ctx context.Context, workflowID primitive.ObjectID, before time.Time) (int64, error) {
+	result, err := r.collection.DeleteMany(ctx, bson.M{
+		"workflow_id": workflowID,
+		"status":      bson.M{"$in": finishedStatuses},
+		"started_at":  bson.M{"$lt": before},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// DeleteFinishedBeyond keeps the latest finished executions of a workflow
+// and deletes the older ones
+func (r *executionRepository) DeleteFinishedBeyond(ctx context.Context, workflowID primitive.ObjectID, keep int) (int64, error) {
+	query := bson.M{
+		"workflow_id": workflowID,
+		"status":      bson.M{"$in": finishedStatuses},
+	}
+
+	// Find the newest execution past the ones to keep
+	opts := options.FindOne().
+		SetSort(bson.D{{Key: "started_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64(keep)).
+		SetProjection(bson.M{"started_at": 1})
+
+	var oldest struct {
+		ID        primitive.ObjectID `bson:"_id"`
+		StartedAt time.Time          `bson:"started_at"`
+	}
+	if err := r.collection.FindOne(ctx, query, opts).Decode(&oldest); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	query["$or"] = bson.A{
+		bson.M{"started_at": bson.M{"$lt": oldest.StartedAt}},
+		bson.M{"started_at": oldest.StartedAt, "_id": bson.M{"$lte": oldest.ID}},
+	}
+	result, err := r.collection.DeleteMany(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// PurgePayloads drops the node inputs and outputs of the completed
+// executions of a workflow, keeping their statuses, timings and errors
+func (r *executionRepository) PurgePayloads(ctx context.Context, workflowID primitive.ObjectID) (int64, error) {
+	result, err := r.collection.UpdateMany(ctx,
+		bson.M{
+			"workflow_id":     workflowID,
+			"status":          domain.ExecutionStatusCompleted,
+			"payloads_purged": bson.M{"$ne": true},
+			"node_logs.0":     bson.M{"$exists": true},
+		},
+		bson.M{
+			"$unset": bson.M{
+				"node_logs.$[].input": "", "node_logs.$[].output": "",
+				"node_logs.$[].sealed_input": "", "node_logs.$[].sealed_output": "",
+			},
+			"$set": bson.M{"payloads_purged": true},
+		},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// executionStatuses are the statuses counted by Stats
+var executionStatuses = []domain.ExecutionStatus{
+	domain.ExecutionStatusPending,
+	domain.ExecutionStatusRunning,
+	domain.ExecutionStatusCompleted,
+	domain.ExecutionStatusFailed,
+	domain.ExecutionStatusCancelled,
+}
+
+// statsGroup returns the stages grouping executions by id, counting them by
+// status and describing the durations of those that ran to the end
+func statsGroup(id any) bson.A {
+	duration := bson.M{"$cond": bson.A{
+		bson.M{"$in": bson.A{"$status", bson.A{domain.ExecutionStatusCompleted, domain.ExecutionStatusFailed}}},
+		"$duration",
+		nil,
+	}}
+
+	group := bson.M{
+		"_id":   id,
+		"total": bson.M{"$sum": 1},
+		"percentiles": bson.M{"$percentile": bson.M{
+			"input":  duration,
+			"p":      bson.A{0.5, 0.95, 0.99},
+			"method": "approximate",
+		}},
+		"avg": bson.M{"$avg": duration},
+		"max": bson.M{"$max": duration},
+	}
+	for _, status := range executionStatuses {
+		group[string(status)] = bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$status", status}}, 1, 0}}}
+	}
+
+	percentile := func(i int) bson.M {
+		return bson.M{"$ifNull": bson.A{bson.M{"$arrayElemAt": bson.A{"$percentiles", i}}, 0}}
+	}
+	return bson.A{
+		bson.M{"$group": group},
+		bson.M{"$set": bson.M{"latency": bson.M{
+			"p50": percentile(0),
+			"p95": percentile(1),
+			"p99": percentile(2),
+			"avg": bson.M{"$ifNull": bson.A{"$avg", 0}},
+			"max": bson.M{"$ifNull": bson.A{"$max", 0}},
+		}}},
+	}
+}
+
+// Stats counts the executions started in a time range by status, in total
+// and per interval, with their latency percentiles, the nodes that failed
+// most often and the most common error messages
+func (r *executionRepository) Stats(ctx context.Context, filter ExecutionStatsFilter) (*domain.ExecutionStats, error) {
+	stats := &domain.ExecutionStats{
+		From:            filter.From,
+		To:              filter.To,
+		Buckets:         []domain.ExecutionStatsBucket{},
+		TopFailingNodes: []domain.FailingNodeStats{},
+		CommonErrors:    []domain.ErrorStats{},
+	}
+	if filter.Interval <= 0 {
+		return nil, fmt.Errorf("interval must be positive")
+	}
+	buckets := int(filter.To.Sub(filter.From) / filter.Interval)
+	if filter.To.Sub(filter.From)%filter.Interval != 0 {
+		buckets++
+	}
+	for i := 0; i < buckets; i++ {
+		stats.Buckets = append(stats.Buckets, domain.ExecutionStatsBucket{Start: filter.From.Add(time.Duration(i) * filter.Interval)})
+	}
+	if len(filter.WorkflowIDs) == 0 {
+		return stats, nil
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	bucketIndex := bson.M{"$toLong": bson.M{"$floor": bson.M{"$divide": bson.A{
+		bson.M{"$subtract": bson.A{"$started_at", filter.From}},
+		filter.Interval.Milliseconds(),
+	}}}}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"workflow_id": bson.M{"$in": filter.WorkflowIDs},
+			"started_at":  bson.M{"$gte": filter.From, "$lt": filter.To},
+		}}},
+		// Keep the executions in order so $last picks the latest values
+		{{Key: "$sort", Value: bson.D{{Key: "started_at", Value: 1}}}},
+		{{Key: "$facet", Value: bson.M{
+			"summary": statsGroup(nil),
+			"buckets": statsGroup(bucketIndex),
+			"nodes": bson.A{
+				bson.M{"$match": bson.M{"node_logs.status": domain.ExecutionStatusFailed}},
+				bson.M{"$unwind": "$node_logs"},
+				bson.M{"$match": bson.M{"node_logs.status": domain.ExecutionStatusFailed}},
+				bson.M{"$group": bson.M{
+					"_id":            bson.M{"workflow_id": "$workflow_id", "node_id": "$node_logs.node_id"},
+					"workflow_name":  bson.M{"$last": "$workflow_name"},
+					"node_label":     bson.M{"$last": "$node_logs.node_label"},
+					"node_type":      bson.M{"$last": "$node_logs.node_type"},
+					"failures":       bson.M{"$sum": 1},
+					"last_error":     bson.M{"$last": "$node_logs.error"},
+					"last_failed_at": bson.M{"$last": "$started_at"},
+				}},
+				bson.M{"$sort": bson.D{{Key: "failures", Value: -1}, {Key: "last_failed_at", Value: -1}}},
+				bson.M{"$limit": limit},
+				bson.M{"$set": bson.M{"workflow_id": "$_id.workflow_id", "node_id": "$_id.node_id"}},
+			},
+			"errors": bson.A{
+				bson.M{"$match": bson.M{
+					"status":        domain.ExecutionStatusFailed,
+					"error.message": bson.M{"$nin": bson.A{nil, ""}},
+				}},
+				bson.M{"$group": bson.M{
+					"_id":          "$error.message",
+					"code":         bson.M{"$last": "$error.code"},
+					"node_id":      bson.M{"$last": "$error.node_id"},
+					"count":        bson.M{"$sum": 1},
+					"last_seen_at": bson.M{"$last": "$started_at"},
+					"workflow_ids": bson.M{"$addToSet": "$workflow_id"},
+				}},
+				bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "last_seen_at", Value: -1}}},
+				bson.M{"$limit": limit},
+				bson.M{"$set": bson.M{"message": "$_id"}},
+			},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Summary []domain.ExecutionStats `bson:"summary"`
+		Buckets []struct {
+			Index                  int64 `bson:"_id"`
+			domain.ExecutionCounts `bson:",inline"`
+			Latency                domain.LatencyStats `bson:"latency"`
+		} `bson:"buckets"`
+		Nodes  []domain.FailingNodeStats `bson:"nodes"`
+		Errors []domain.ErrorStats       `bson:"errors"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return stats, nil
+	}
+	result := results[0]
+
+	if len(result.Summary) > 0 {
+		stats.ExecutionCounts = result.Summary[0].ExecutionCounts
+		stats.Latency = result.Summary[0].Latency
+	}
+	if finished := stats.Completed + stats.Failed + stats.Cancelled; finished > 0 {
+		stats.SuccessRate = float64(stats.Completed) / float64(finished)
+	}
+	for _, bucket := range result.Buckets {
+		if bucket.Index >= 0 && int(bucket.Index) < len(stats.Buckets) {
+			stats.Buckets[bucket.Index].ExecutionCounts = bucket.ExecutionCounts
+			stats.Buckets[bucket.Index].Latency = bucket.Latency
+		}
+	}
+	if result.Nodes != nil {
+		stats.TopFailingNodes = result.Nodes
+	}
+	if result.Errors != nil {
+		stats.CommonErrors = result.Errors
+	}
+	return stats, nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/nodetl/nodetl/internal/domain"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -66,11 +67,75 @@ type NodeTypeFilter struct {
 type ExecutionRepository interface {
 	Create(ctx context.Context, execution *domain.Execution) error
 	GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Execution, error)
-	GetByWorkflowID(ctx context.Context, workflowID primitive.ObjectID, page, pageSize int) ([]domain.Execution, int64, error)
-	GetByWorkflowIDs(ctx context.Context, workflowIDs []primitive.ObjectID, page, pageSize int) ([]domain.Execution, int64, error)
+	Search(ctx context.Context, filter ExecutionFilter) (*ExecutionPage, error)
+	Stats(ctx context.Context, filter ExecutionStatsFilter) (*domain.ExecutionStats, error)
 	Update(ctx context.Context, execution *domain.Execution) error
+	ReplaceIfStatus(ctx context.Context, execution *domain.Execution, status domain.ExecutionStatus) (bool, error)
+	AppendNodeLog(ctx context.Context, id primitive.ObjectID, nodeLog domain.NodeExecutionLog) error
+	Heartbeat(ctx context.Context, id primitive.ObjectID) (bool, error)
+	RequestCancel(ctx context.Context, id primitive.ObjectID) (bool, error)
+	FindStale(ctx context.Context, staleBefore time.Time, limit int) ([]domain.Execution, error)
+	ReplaceIfStale(ctx context.Context, execution *domain.Execution, staleBefore time.Time) (bool, error)
 	Delete(ctx context.Context, id primitive.ObjectID) error
 	GetLatest(ctx context.Context, workflowID primitive.ObjectID, limit int) ([]domain.Execution, error)
+	DeleteFinishedBefore(ctx context.Context, workflowID primitive.ObjectID, before time.Time) (int64, error)
+	DeleteFinishedBeyond(ctx context.Context, workflowID primitive.ObjectID, keep int) (int64, error)
+	PurgePayloads(ctx context.Context, workflowID primitive.ObjectID) (int64, error)
+}
+
+// ExecutionFilter selects the executions of one or more workflows. Empty
+// fields match all executions.
+type ExecutionFilter struct {
+	WorkflowIDs  []primitive.ObjectID
+	Statuses     []domain.ExecutionStatus
+	TriggerTypes []string
+	StartedFrom  *time.Time
+	StartedTo    *time.Time
+	MinDuration  *int64 // milliseconds
+	MaxDuration  *int64 // milliseconds
+	TraceID      string
+	FailedNode   string // ID of a node that failed during the execution
+	ErrorText    string // Case-insensitive text in the error message
+	SortBy       string // ExecutionSortStartedAt (default) or ExecutionSortDuration
+	Ascending    bool
+	Cursor       string // Returned as NextCursor by the previous page
+	Page         int    // Offset pagination, ignored with a cursor
+	PageSize     int
+}
+
+// ExecutionPage is a page of executions matching a filter
+type ExecutionPage struct {
+	Executions []domain.Execution
+	Total      int64
+	NextCursor string // Empty on the last page
+}
+
+// ExecutionStatsFilter selects the executions summarized by Stats
+type ExecutionStatsFilter struct {
+	WorkflowIDs []primitive.ObjectID
+	From        time.Time // Start of the first bucket
+	To          time.Time // Exclusive
+	Interval    time.Duration
+	Limit       int // Number of failing nodes and errors to return
+}
+
+// ExecutionJobRepository defines the interface for the async execution queue
+type ExecutionJobRepository interface {
+	Enqueue(ctx context.Context, job *domain.ExecutionJob) error
+	ClaimNext(ctx context.Context, workerID string) (*domain.ExecutionJob, error)
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	Requeue(ctx context.Context, job *domain.ExecutionJob) error
+	FindStaleClaims(ctx context.Context, lockedBefore time.Time, limit int) ([]domain.ExecutionJob, error)
+	Release(ctx context.Context, job *domain.ExecutionJob) (bool, error)
+	DeleteByExecutionID(ctx context.Context, executionID primitive.ObjectID) error
+}
+
+// ScheduleLeaseRepository defines the interface for the leases that keep a
+// schedule tick from running on more than one instance
+type ScheduleLeaseRepository interface {
+	Acquire(ctx context.Context, id string, scheduledAt time.Time, holder string, ttl time.Duration) (bool, error)
+	Renew(ctx context.Context, id, holder string, ttl time.Duration) error
+	Release(ctx context.Context, id, holder string) error
 }
 
 // MappingRepository defines the interface for field mapping data operations
@@ -82,3 +147,12 @@ type MappingRepository interface {
 	Update(ctx context.Context, mapping *domain.FieldMapping) error
 	Delete(ctx context.Context, id primitive.ObjectID) error
 }
+
+// CredentialRepository defines the interface for credential data operations
+type CredentialRepository interface {
+	Create(ctx context.Context, credential *domain.Credential) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Credential, error)
+	GetAll(ctx context.Context) ([]domain.Credential, error)
+	Update(ctx context.Context, credential *domain.Credential) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
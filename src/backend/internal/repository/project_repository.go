@@ -162,6 +162,7 @@ func (r *ProjectRepository) Update(ctx context.Context, id string, project *doma
 			"path_prefix": project.PathPrefix,
 			"status":      project.Status,
 			"is_locked":   project.IsLocked,
+			"retention":   project.Retention,
 			"updated_at":  project.UpdatedAt,
 		},
 	}
@@ -170,6 +171,25 @@ func (r *ProjectRepository) Update(ctx context.Context, id string, project *doma
 	return err
 }
 
+// UpdateEnvironments replaces the environments of a project
+func (r *ProjectRepository) UpdateEnvironments(ctx context.Context, id string, environments []domain.Environment, defaultEnvironment string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"environments":        environments,
+			"default_environment": defaultEnvironment,
+			"updated_at":          time.Now(),
+		},
+	}
+
+	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	return err
+}
+
 // Delete deletes a project by ID
 func (r *ProjectRepository) Delete(ctx context.Context, id string) error {
 	objectID, err := primitive.ObjectIDFromHex(id)
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/nodetl/nodetl/pkg/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// scheduleLeaseRepository keeps one document per schedule trigger with the
+// last tick that was claimed and who holds it until when
+type scheduleLeaseRepository struct {
+	collection *mongo.Collection
+}
+
+// NewScheduleLeaseRepository creates a new schedule lease repository
+func NewScheduleLeaseRepository(client *mongodb.Client) ScheduleLeaseRepository {
+	return &scheduleLeaseRepository{collection: client.Collection(mongodb.CollectionScheduleLeases)}
+}
+
+// Acquire claims the tick at scheduledAt for the holder. It fails when that
+// tick was already claimed or another instance still holds the lease.
+func (r *scheduleLeaseRepository) Acquire(ctx context.Context, id string, scheduledAt time.Time, holder string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{
+			"_id":          id,
+			"scheduled_at": bson.M{"$lt": scheduledAt},
+			"locked_until": bson.M{"$lt": now},
+		},
+		bson.M{"$set": bson.M{
+			"scheduled_at": scheduledAt,
+			"holder":       holder,
+			"locked_until": now.Add(ttl),
+		}},
+		options.Update().SetUpsert(true),
+	)
+	// The filter did not match an existing lease, so the upsert collided with it
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Renew extends a lease the holder still has
+func (r *scheduleLeaseRepository) Renew(ctx context.Context, id, holder string, ttl time.Duration) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "holder": holder},
+		bson.M{"$set": bson.M{"locked_until": time.Now().Add(ttl)}},
+	)
+	return err
+}
+
+// Release lets the next tick run on any instance
+func (r *scheduleLeaseRepository) Release(ctx context.Context, id, holder string) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "holder": holder},
+		bson.M{"$set": bson.M{"locked_until": time.Now()}},
+	)
+	return err
+}
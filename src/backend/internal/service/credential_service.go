@@ -0,0 +1,321 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nodetl/nodetl/internal/domain"
+	"github.com/nodetl/nodetl/internal/repository"
+	"github.com/nodetl/nodetl/pkg/secrets"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrCredentialsDisabled = errors.New("credentials are disabled: CREDENTIALS_ENCRYPTION_KEY is not set")
+	ErrInvalidCredential   = errors.New("invalid credential")
+)
+
+// tokenExpiryMargin renews OAuth2 tokens this long before they expire
+const tokenExpiryMargin = time.Minute
+
+// CredentialService stores credentials encrypted and applies them to the
+// requests nodes make
+type CredentialService struct {
+	credentialRepo repository.CredentialRepository
+	cipher         *secrets.Cipher // nil when no encryption key is configured
+	httpClient     *http.Client
+
+	tokensMu sync.Mutex
+	tokens   map[primitive.ObjectID]*tokenEntry
+}
+
+// tokenEntry caches the OAuth2 token of one credential. Its lock makes
+// concurrent requests wait for a single token request.
+type tokenEntry struct {
+	mu    sync.Mutex
+	token oauthToken
+}
+
+// oauthToken is an access token fetched with the client credentials grant
+type oauthToken struct {
+	accessToken string
+	tokenType   string
+	expiresAt   time.Time
+	version     time.Time // UpdatedAt of the credential the token was fetched with
+}
+
+// NewCredentialService creates a new credential service. Without a cipher
+// credentials can be listed but not created or used.
+func NewCredentialService(credentialRepo repository.CredentialRepository, cipher *secrets.Cipher) *CredentialService {
+	return &CredentialService{
+		credentialRepo: credentialRepo,
+		cipher:         cipher,
+		httpClient:     &http.Client{Timeout: 30 * time.Second},
+		tokens:         make(map[primitive.ObjectID]*tokenEntry),
+	}
+}
+
+// Enabled reports whether an encryption key is configured
+func (s *CredentialService) Enabled() bool {
+	return s.cipher != nil
+}
+
+// CreateCredential validates and stores a new credential
+func (s *CredentialService) CreateCredential(ctx context.Context, req *domain.CreateCredentialRequest, createdBy primitive.ObjectID) (*domain.Credential, error) {
+	if s.cipher == nil {
+		return nil, ErrCredentialsDisabled
+	}
+	if err := domain.ValidateCredential(req.Type, req.Config, req.Secret); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredential, err)
+	}
+
+	credential := &domain.Credential{
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Type:        req.Type,
+		Config:      req.Config,
+		CreatedBy:   createdBy,
+	}
+	if err := s.seal(credential, req.Secret); err != nil {
+		return nil, err
+	}
+	if err := s.credentialRepo.Create(ctx, credential); err != nil {
+		return nil, err
+	}
+	return credential, nil
+}
+
+// GetCredential returns a credential without its secret values
+func (s *CredentialService) GetCredential(ctx context.Context, id primitive.ObjectID) (*domain.Credential, error) {
+	return s.credentialRepo.GetByID(ctx, id)
+}
+
+// ListCredentials returns all credentials without their secret values
+func (s *CredentialService) ListCredentials(ctx context.Context) ([]domain.Credential, error) {
+	credentials, err := s.credentialRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if credentials == nil {
+		credentials = []domain.Credential{}
+	}
+	return credentials, nil
+}
+
+// UpdateCredential changes the settings of a credential. Secret values in
+// the request replace the stored ones; the others are kept.
+func (s *CredentialService) UpdateCredential(ctx context.Context, id primitive.ObjectID, req *domain.UpdateCredentialRequest) (*domain.Credential, error) {
+	if s.cipher == nil {
+		return nil, ErrCredentialsDisabled
+	}
+	credential, err := s.credentialRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := s.open(credential)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range req.Secret {
+		secret[key] = value
+	}
+
+	if req.Name != nil {
+		credential.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		credential.Description = *req.Description
+	}
+	if req.Config != nil {
+		credential.Config = req.Config
+	}
+	if err := domain.ValidateCredential(credential.Type, credential.Config, secret); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredential, err)
+	}
+
+	if err := s.seal(credential, secret); err != nil {
+		return nil, err
+	}
+	if err := s.credentialRepo.Update(ctx, credential); err != nil {
+		return nil, err
+	}
+	s.forgetToken(id)
+	return credential, nil
+}
+
+// DeleteCredential removes a credential. Nodes that still refer to it fail.
+func (s *CredentialService) DeleteCredential(ctx context.Context, id primitive.ObjectID) error {
+	if err := s.credentialRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.forgetToken(id)
+	return nil
+}
+
+// Authorize adds the credential with the given ID to an outgoing request
+func (s *CredentialService) Authorize(ctx context.Context, credentialID string, req *http.Request) error {
+	if s.cipher == nil {
+		return ErrCredentialsDisabled
+	}
+	id, err := primitive.ObjectIDFromHex(credentialID)
+	if err != nil {
+		return fmt.Errorf("invalid credential ID %q", credentialID)
+	}
+	credential, err := s.credentialRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	secret, err := s.open(credential)
+	if err != nil {
+		return err
+	}
+
+	switch credential.Type {
+	case domain.CredentialTypeAPIKey:
+		if credential.Config["in"] == "query" {
+			query := req.URL.Query()
+			query.Set(credential.Config["name"], secret["key"])
+			req.URL.RawQuery = query.Encode()
+		} else {
+			req.Header.Set(credential.Config["name"], secret["key"])
+		}
+	case domain.CredentialTypeBasicAuth:
+		req.SetBasicAuth(credential.Config["username"], secret["password"])
+	case domain.CredentialTypeBearerToken:
+		req.Header.Set("Authorization", "Bearer "+secret["token"])
+	case domain.CredentialTypeOAuth2ClientCredentials:
+		token, err := s.clientCredentialsToken(ctx, credential, secret)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", token.tokenType+" "+token.accessToken)
+	default:
+		return fmt.Errorf("unknown credential type %q", credential.Type)
+	}
+	return nil
+}
+
+// seal encrypts the secret values into the credential
+func (s *CredentialService) seal(credential *domain.Credential, secret map[string]string) error {
+	plaintext, err := json.Marshal(secret)
+	if err != nil {
+		return err
+	}
+	encrypted, err := s.cipher.Encrypt(plaintext)
+	if err != nil {
+		return err
+	}
+	credential.EncryptedSecret = encrypted
+	credential.SecretKeys = domain.SecretKeysOf(secret)
+	return nil
+}
+
+// open decrypts the secret values of a credential
+func (s *CredentialService) open(credential *domain.Credential) (map[string]string, error) {
+	plaintext, err := s.cipher.Decrypt(credential.EncryptedSecret)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decrypt credential %q: %w", credential.Name, err)
+	}
+	secret := make(map[string]string)
+	if err := json.Unmarshal(plaintext, &secret); err != nil {
+		return nil, fmt.Errorf("cannot decode credential %q: %w", credential.Name, err)
+	}
+	return secret, nil
+}
+
+// clientCredentialsToken returns a cached access token or requests a new one
+// from the token endpoint
+func (s *CredentialService) clientCredentialsToken(ctx context.Context, credential *domain.Credential, secret map[string]string) (oauthToken, error) {
+	s.tokensMu.Lock()
+	entry, ok := s.tokens[credential.ID]
+	if !ok {
+		entry = &tokenEntry{}
+		s.tokens[credential.ID] = entry
+	}
+	s.tokensMu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	if entry.token.accessToken != "" && entry.token.version.Equal(credential.UpdatedAt) && time.Now().Before(entry.token.expiresAt) {
+		return entry.token, nil
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if scope := credential.Config["scope"]; scope != "" {
+		form.Set("scope", scope)
+	}
+	if audience := credential.Config["audience"]; audience != "" {
+		form.Set("audience", audience)
+	}
+	clientID, clientSecret := credential.Config["clientId"], secret["clientSecret"]
+	if credential.Config["authStyle"] == "body" {
+		form.Set("client_id", clientID)
+		form.Set("client_secret", clientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, credential.Config["tokenUrl"], strings.NewReader(form.Encode()))
+	if err != nil {
+		return oauthToken{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if credential.Config["authStyle"] != "body" {
+		req.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(clientSecret))
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return oauthToken{}, fmt.Errorf("token request for credential %q failed: %w", credential.Name, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return oauthToken{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return oauthToken{}, fmt.Errorf("token endpoint of credential %q returned status %d", credential.Name, resp.StatusCode)
+	}
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil || result.AccessToken == "" {
+		return oauthToken{}, fmt.Errorf("token endpoint of credential %q returned no access token", credential.Name)
+	}
+
+	token := oauthToken{
+		accessToken: result.AccessToken,
+		tokenType:   "Bearer",
+		version:     credential.UpdatedAt,
+	}
+	// Other token types are passed through; bearer is normalized to its usual spelling
+	if result.TokenType != "" && !strings.EqualFold(result.TokenType, "bearer") {
+		token.tokenType = result.TokenType
+	}
+	// Tokens without a lifetime are used for this request only
+	if result.ExpiresIn > 0 {
+		token.expiresAt = time.Now().Add(time.Duration(result.ExpiresIn)*time.Second - tokenExpiryMargin)
+		entry.token = token
+	}
+	return token, nil
+}
+
+// forgetToken drops the cached token of a changed or deleted credential
+func (s *CredentialService) forgetToken(id primitive.ObjectID) {
+	s.tokensMu.Lock()
+	delete(s.tokens, id)
+	s.tokensMu.Unlock()
+}
@@ -0,0 +1,228 @@
+// Package tracing sets up OpenTelemetry tracing. Executions and their nodes
+// are recorded as spans, W3C traceparent headers are read from incoming
+// webhooks and sent with the requests of HTTP nodes, so a request can be
+// followed from the caller through a workflow into downstream services.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/nodetl/nodetl/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Span exporters
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+	ExporterOTLP   = "otlp"
+)
+
+const instrumentationName = "github.com/nodetl/nodetl"
+
+// Init installs the global tracer provider and the W3C trace context
+// propagator. Spans are created even without an exporter, so executions
+// always get a trace ID callers can correlate. The returned function flushes
+// the remaining spans and stops the exporter.
+func Init(ctx context.Context, cfg config.TracingConfig) (func(context.Context) error, error) {
+	var (
+		exporter sdktrace.SpanExporter
+		closer   io.Closer
+		err      error
+	)
+	switch strings.ToLower(cfg.Exporter) {
+	case "", ExporterNone:
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case ExporterFile:
+		var file *os.File
+		file, err = os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open trace file: %w", err)
+		}
+		closer = file
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(file))
+	case ExporterOTLP:
+		// Endpoint, headers and TLS are read from the standard
+		// OTEL_EXPORTER_OTLP_* variables
+		exporter, err = otlptracehttp.New(ctx)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q: use none, stdout, file or otlp", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s trace exporter: %w", cfg.Exporter, err)
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(attribute.String("service.name", cfg.ServiceName)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		sdktrace.WithIDGenerator(idGenerator{}),
+	}
+	if exporter != nil {
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+	provider := sdktrace.NewTracerProvider(opts...)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closer != nil {
+			closer.Close()
+		}
+		return err
+	}, nil
+}
+
+// Tracer returns the tracer of the server
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Extract returns ctx with the trace context of incoming request headers,
+// so spans started from it continue the caller's trace
+func Extract(ctx context.Context, header http.Header) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// NewTrace returns the trace a new execution belongs to. Executions started
+// within a trace, such as from a webhook whose caller sent a traceparent or
+// by another workflow, continue it and also return the traceparent header to
+// resume it from; others get a new trace ID.
+func NewTrace(ctx context.Context) (traceID string, traceparent string) {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		carrier := propagation.MapCarrier{}
+		propagation.TraceContext{}.Inject(ctx, carrier)
+		return sc.TraceID().String(), carrier.Get("traceparent")
+	}
+	return newTraceID().String(), ""
+}
+
+// Resume returns ctx with the trace NewTrace returned, so the span of an
+// execution that runs later, e.g. from the queue, joins it
+func Resume(ctx context.Context, traceID string, traceparent string) context.Context {
+	if traceparent != "" {
+		carrier := propagation.MapCarrier{"traceparent": traceparent}
+		parent := propagation.TraceContext{}.Extract(ctx, carrier)
+		if trace.SpanContextFromContext(parent).IsValid() {
+			return parent
+		}
+	}
+	if id, err := trace.TraceIDFromHex(traceID); err == nil {
+		// Root spans started from ctx take this trace ID
+		return context.WithValue(trace.ContextWithSpanContext(ctx, trace.SpanContext{}), traceIDKey{}, id)
+	}
+	return ctx
+}
+
+// End records the outcome of a span and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Transport records the requests sent through the next round tripper as
+// client spans and sends the traceparent header with them
+func Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &transport{next: next}
+}
+
+type transport struct {
+	next http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := Tracer().Start(req.Context(), "HTTP "+req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("server.address", req.URL.Hostname()),
+			attribute.String("url.path", req.URL.Path),
+		),
+	)
+	defer span.End()
+
+	// Round trippers must not change the caller's request
+	req = req.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= 400 {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+	return resp, nil
+}
+
+// traceIDKey holds the trace ID Resume assigns to the next root span
+type traceIDKey struct{}
+
+// idGenerator generates random IDs, except for root spans that Resume gave a
+// trace ID
+type idGenerator struct{}
+
+// NewIDs implements sdktrace.IDGenerator
+func (idGenerator) NewIDs(ctx context.Context) (trace.TraceID, trace.SpanID) {
+	if id, ok := ctx.Value(traceIDKey{}).(trace.TraceID); ok && id.IsValid() {
+		return id, newSpanID()
+	}
+	return newTraceID(), newSpanID()
+}
+
+// NewSpanID implements sdktrace.IDGenerator
+func (idGenerator) NewSpanID(ctx context.Context, traceID trace.TraceID) trace.SpanID {
+	return newSpanID()
+}
+
+func newTraceID() trace.TraceID {
+	var id trace.TraceID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+func newSpanID() trace.SpanID {
+	var id trace.SpanID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
@@ -49,15 +49,18 @@ func (c *Client) Close(ctx context.Context) error {
 
 // Collection names
 const (
-	CollectionWorkflows     = "workflows"
-	CollectionNodeTypes     = "node_types"
-	CollectionSchemas       = "schemas"
-	CollectionExecutions    = "executions"
-	CollectionEndpoints     = "endpoints"
-	CollectionMappings      = "mappings"
-	CollectionUsers         = "users"
-	CollectionRoles         = "roles"
-	CollectionRefreshTokens = "refresh_tokens"
-	CollectionInvitations   = "invitations"
-	CollectionSettings      = "settings"
+	CollectionWorkflows      = "workflows"
+	CollectionNodeTypes      = "node_types"
+	CollectionSchemas        = "schemas"
+	CollectionExecutions     = "executions"
+	CollectionEndpoints      = "endpoints"
+	CollectionMappings       = "mappings"
+	CollectionUsers          = "users"
+	CollectionRoles          = "roles"
+	CollectionRefreshTokens  = "refresh_tokens"
+	CollectionInvitations    = "invitations"
+	CollectionSettings       = "settings"
+	CollectionExecutionJobs  = "execution_jobs"
+	CollectionCredentials    = "credentials"
+	CollectionScheduleLeases = "schedule_leases"
 )
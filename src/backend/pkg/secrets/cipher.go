@@ -0,0 +1,84 @@
+// Package secrets encrypts values stored at rest with AES-256-GCM
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrInvalidCiphertext is returned when a value cannot be decrypted, e.g.
+// because it was encrypted with a different key
+var ErrInvalidCiphertext = errors.New("invalid ciphertext")
+
+// Cipher encrypts and decrypts values with a 256-bit key
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// NewCipher creates a cipher from a 32-byte key written as 64 hex characters
+// or as base64
+func NewCipher(key string) (*Cipher, error) {
+	raw, err := decodeKey(key)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+func decodeKey(key string) ([]byte, error) {
+	if key == "" {
+		return nil, errors.New("encryption key is empty")
+	}
+	if len(key) == 64 {
+		if raw, err := hex.DecodeString(key); err == nil {
+			return raw, nil
+		}
+	}
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(raw) != 32 {
+		return nil, fmt.Errorf("encryption key must be 32 bytes written as 64 hex characters or base64")
+	}
+	return raw, nil
+}
+
+// Encrypt seals the plaintext with a random nonce and returns the nonce and
+// ciphertext encoded as base64
+func (c *Cipher) Encrypt(plaintext []byte) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	sealed := c.aead.Seal(nonce, nonce, plaintext, nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value returned by Encrypt
+func (c *Cipher) Decrypt(encoded string) ([]byte, error) {
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+	size := c.aead.NonceSize()
+	if len(sealed) < size {
+		return nil, ErrInvalidCiphertext
+	}
+	plaintext, err := c.aead.Open(nil, sealed[:size], sealed[size:], nil)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+	return plaintext, nil
+}